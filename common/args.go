@@ -13,20 +13,55 @@ var (
 )
 
 type Arguments struct {
-	Cache  string   // Argument for cache folder path
-	Config string   // Argument for config file path
-	Lock   string   // Argument for lock file path
-	Log    string   // Argument for log file path
-	VVV    bool     // Argument for very very verbose mode
-	VV     bool     // Argument for very verbose mode
-	V      bool     // Argument for verbose mode
-	P      []string // Argument for positional values
-	Dbus   struct {
+	Cache     string   // Argument for cache folder path
+	Config    string   // Argument for config file path
+	Lock      string   // Argument for lock file path
+	Log       string   // Argument for log file path
+	LogFormat string   // Argument for log output format
+	Backend   string   // Argument for display server backend
+	Replace   bool     // Argument for replace running instance flag
+	DryRun    bool     // Argument for dry-run simulation flag
+	VVV       bool     // Argument for very very verbose mode
+	VV        bool     // Argument for very verbose mode
+	V         bool     // Argument for verbose mode
+	P         []string // Argument for positional values
+	Dbus      struct {
 		Listen   bool     // Argument for dbus listen flag
 		Method   string   // Argument for dbus method name
 		Property string   // Argument for dbus property name
 		P        []string // Argument for dbus positional values
 	}
+	Caches struct {
+		Command string   // Argument for cache command name
+		P       []string // Argument for cache positional values
+	}
+	Rules struct {
+		Command string   // Argument for rules command name
+		P       []string // Argument for rules positional values
+	}
+	Keys struct {
+		Command string   // Argument for keys command name
+		Persist bool     // Argument for keys persist flag
+		P       []string // Argument for keys positional values
+	}
+	Menu struct {
+		Command string   // Argument for menu command name
+		P       []string // Argument for menu positional values
+	}
+	Inspect struct {
+		Enabled bool     // Argument for inspect command flag
+		Click   bool     // Argument for inspect window selection by click
+		P       []string // Argument for inspect positional values
+	}
+	Stats struct {
+		Enabled bool // Argument for stats command flag
+	}
+	Bench struct {
+		Enabled    bool // Argument for bench command flag
+		Windows    int  // Argument for number of bench windows
+		Iterations int  // Argument for number of bench iterations
+		Simulate   bool // Argument for bench simulation flag
+	}
 }
 
 func InitArgs(introspect map[string][]string) {
@@ -36,6 +71,10 @@ func InitArgs(introspect map[string][]string) {
 	flag.StringVar(&Args.Config, "config", filepath.Join(ConfigFolderPath(Build.Name), "config.toml"), "config file path")
 	flag.StringVar(&Args.Lock, "lock", filepath.Join(os.TempDir(), fmt.Sprintf("%s.lock", Build.Name)), "lock file path")
 	flag.StringVar(&Args.Log, "log", filepath.Join(os.TempDir(), fmt.Sprintf("%s.log", Build.Name)), "log file path")
+	flag.StringVar(&Args.LogFormat, "log-format", "text", "log output format (text | json)")
+	flag.StringVar(&Args.Backend, "backend", "auto", "display server backend (auto | x11 | wlroots, wlroots requires enable-experimental-backend)")
+	flag.BoolVar(&Args.DryRun, "dry-run", false, "log window requests instead of executing them")
+	flag.BoolVar(&Args.Replace, "replace", false, "replace running instance")
 	flag.BoolVar(&Args.VVV, "vvv", false, "very very verbose mode")
 	flag.BoolVar(&Args.VV, "vv", false, "very verbose mode")
 	flag.BoolVar(&Args.V, "v", false, "verbose mode")
@@ -58,6 +97,34 @@ func InitArgs(introspect map[string][]string) {
 	dbus.StringVar(&Args.Dbus.Property, "property", "", "dbus property reader")
 	Args.Dbus.P = []string{}
 
+	cache := flag.NewFlagSet("cache", flag.ExitOnError)
+	cache.StringVar(&Args.Cache, "cache", Args.Cache, "cache folder path")
+	cache.StringVar(&Args.Config, "config", Args.Config, "config file path")
+	Args.Caches.P = []string{}
+
+	rules := flag.NewFlagSet("rules", flag.ExitOnError)
+	Args.Rules.P = []string{}
+
+	keys := flag.NewFlagSet("keys", flag.ExitOnError)
+	keys.BoolVar(&Args.Keys.Persist, "persist", false, "persist key binding changes in keys.toml of the cache folder")
+	keys.StringVar(&Args.Cache, "cache", Args.Cache, "cache folder path")
+	Args.Keys.P = []string{}
+
+	menu := flag.NewFlagSet("menu", flag.ExitOnError)
+	Args.Menu.P = []string{}
+
+	inspect := flag.NewFlagSet("inspect", flag.ExitOnError)
+	inspect.BoolVar(&Args.Inspect.Click, "click", false, "select window by pointer click")
+	Args.Inspect.P = []string{}
+
+	stats := flag.NewFlagSet("stats", flag.ExitOnError)
+
+	bench := flag.NewFlagSet("bench", flag.ExitOnError)
+	bench.StringVar(&Args.Config, "config", Args.Config, "config file path")
+	bench.IntVar(&Args.Bench.Windows, "windows", 60, "number of synthetic windows")
+	bench.IntVar(&Args.Bench.Iterations, "iterations", 100, "number of measured iterations")
+	bench.BoolVar(&Args.Bench.Simulate, "simulate", false, "simulate windows without X server")
+
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "dbus":
@@ -94,6 +161,167 @@ func InitArgs(introspect map[string][]string) {
 				dbus.Usage()
 				os.Exit(2)
 			}
+		case "cache":
+
+			// Subcommand line usage text
+			cache.Usage = func() {
+				fmt.Fprintf(cache.Output(), "%s\n\nUsage:\n", Build.Summary)
+				cache.PrintDefaults()
+
+				fmt.Fprintf(cache.Output(), "\nCommands:\n")
+				fmt.Fprintf(cache.Output(), "  %s cache ls\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \tlist cached clients and workspaces per workplace\n")
+				fmt.Fprintf(cache.Output(), "  %s cache show str:id...\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \tprint cache entries with the given ids\n")
+				fmt.Fprintf(cache.Output(), "  %s cache rm str:id... | corrupt\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \tremove cache entries with the given ids or all corrupt entries\n")
+				fmt.Fprintf(cache.Output(), "  %s cache export [str:file]\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \twrite layouts and geometries of all workplaces into an archive\n")
+				fmt.Fprintf(cache.Output(), "  %s cache import [str:file]\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \tread layouts and geometries of all workplaces from an archive\n")
+				fmt.Fprintf(cache.Output(), "  %s cache prune\n", Build.Name)
+				fmt.Fprintf(cache.Output(), "  \tremove cache entries older than configured in cache_prune_*\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(cache, os.Args[2:])
+			Args.Caches.P = cache.Args()
+
+			// Check subcommand line arguments
+			if len(Args.Caches.P) == 0 {
+				cache.Usage()
+				os.Exit(2)
+			}
+			Args.Caches.Command = Args.Caches.P[0]
+			Args.Caches.P = Args.Caches.P[1:]
+		case "rules":
+
+			// Subcommand line usage text
+			rules.Usage = func() {
+				fmt.Fprintf(rules.Output(), "%s\n\nUsage:\n", Build.Summary)
+				rules.PrintDefaults()
+
+				fmt.Fprintf(rules.Output(), "\nCommands:\n")
+				fmt.Fprintf(rules.Output(), "  %s rules test [int:id]\n", Build.Name)
+				fmt.Fprintf(rules.Output(), "  \treport matching ignore and game rules of a window (default = active window)\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(rules, os.Args[2:])
+			Args.Rules.P = rules.Args()
+
+			// Check subcommand line arguments
+			if len(Args.Rules.P) == 0 || Args.Rules.P[0] != "test" {
+				rules.Usage()
+				os.Exit(2)
+			}
+			Args.Rules.Command = Args.Rules.P[0]
+			Args.Rules.P = Args.Rules.P[1:]
+		case "keys":
+
+			// Subcommand line usage text
+			keys.Usage = func() {
+				fmt.Fprintf(keys.Output(), "%s\n\nUsage:\n", Build.Summary)
+				keys.PrintDefaults()
+
+				fmt.Fprintf(keys.Output(), "\nCommands:\n")
+				fmt.Fprintf(keys.Output(), "  %s keys list\n", Build.Name)
+				fmt.Fprintf(keys.Output(), "  \tlist active key bindings and their grab status\n")
+				fmt.Fprintf(keys.Output(), "  %s keys add [-persist] str:action str:key\n", Build.Name)
+				fmt.Fprintf(keys.Output(), "  \tbind a key combination to an action at runtime\n")
+				fmt.Fprintf(keys.Output(), "  %s keys remove [-persist] str:action\n", Build.Name)
+				fmt.Fprintf(keys.Output(), "  \tunbind the key combination of an action at runtime\n")
+				fmt.Fprintf(keys.Output(), "  %s keys doctor\n", Build.Name)
+				fmt.Fprintf(keys.Output(), "  \treport key bindings that are invalid, duplicated or grabbed by another client\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(keys, os.Args[2:])
+			Args.Keys.P = keys.Args()
+
+			// Check subcommand line arguments
+			if len(Args.Keys.P) == 0 || !IsInList(Args.Keys.P[0], []string{"list", "add", "remove", "doctor"}) {
+				keys.Usage()
+				os.Exit(2)
+			}
+			Args.Keys.Command = Args.Keys.P[0]
+			Args.Keys.P = Args.Keys.P[1:]
+		case "menu":
+
+			// Subcommand line usage text
+			menu.Usage = func() {
+				fmt.Fprintf(menu.Output(), "%s\n\nUsage:\n", Build.Summary)
+				menu.PrintDefaults()
+
+				fmt.Fprintf(menu.Output(), "\nCommands:\n")
+				fmt.Fprintf(menu.Output(), "  %s menu windows [str:entry]\n", Build.Name)
+				fmt.Fprintf(menu.Output(), "  \tlist tracked windows for rofi/dmenu, or activate the picked entry\n")
+				fmt.Fprintf(menu.Output(), "  %s menu actions [str:entry]\n", Build.Name)
+				fmt.Fprintf(menu.Output(), "  \tlist actions for rofi/dmenu, or execute the picked entry on the active workspace\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(menu, os.Args[2:])
+			Args.Menu.P = menu.Args()
+
+			// Check subcommand line arguments
+			if len(Args.Menu.P) == 0 || !IsInList(Args.Menu.P[0], []string{"windows", "actions"}) {
+				menu.Usage()
+				os.Exit(2)
+			}
+			Args.Menu.Command = Args.Menu.P[0]
+			Args.Menu.P = Args.Menu.P[1:]
+		case "inspect":
+
+			// Subcommand line usage text
+			inspect.Usage = func() {
+				fmt.Fprintf(inspect.Output(), "%s\n\nUsage:\n", Build.Summary)
+				inspect.PrintDefaults()
+
+				fmt.Fprintf(inspect.Output(), "\nCommands:\n")
+				fmt.Fprintf(inspect.Output(), "  %s inspect [-click] [int:id]\n", Build.Name)
+				fmt.Fprintf(inspect.Output(), "  \tprint window information, tracking status and layout slot (default = active window)\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(inspect, os.Args[2:])
+			Args.Inspect.P = inspect.Args()
+			Args.Inspect.Enabled = true
+		case "stats":
+
+			// Subcommand line usage text
+			stats.Usage = func() {
+				fmt.Fprintf(stats.Output(), "%s\n\nUsage:\n", Build.Summary)
+				stats.PrintDefaults()
+
+				fmt.Fprintf(stats.Output(), "\nCommands:\n")
+				fmt.Fprintf(stats.Output(), "  %s stats\n", Build.Name)
+				fmt.Fprintf(stats.Output(), "  \tprint focus time, re-tiles and layout residency per workspace and window class\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(stats, os.Args[2:])
+			Args.Stats.Enabled = true
+		case "bench":
+
+			// Subcommand line usage text
+			bench.Usage = func() {
+				fmt.Fprintf(bench.Output(), "%s\n\nUsage:\n", Build.Summary)
+				bench.PrintDefaults()
+
+				fmt.Fprintf(bench.Output(), "\nRequirements:\n")
+				fmt.Fprintf(bench.Output(), "  Xvfb and openbox are used unless -simulate is set\n")
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(bench, os.Args[2:])
+			Args.Bench.Enabled = true
+
+			// Check subcommand line arguments
+			if Args.Bench.Windows <= 0 || Args.Bench.Iterations <= 0 {
+				bench.Usage()
+				os.Exit(2)
+			}
 		}
 	}
 }
@@ -1,9 +1,24 @@
 package common
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"archive/tar"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/fs"
 	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
@@ -15,6 +30,53 @@ type Cache[T any] struct {
 	Data   T      // Cache file data
 }
 
+type CacheEntry struct {
+	Id        string // Cache entry id (hashed file name)
+	Path      string // Cache entry file path
+	Workplace string // Cache entry workplace name
+	Type      string // Cache entry type (clients or workspaces)
+	Corrupt   bool   // Cache entry is not parsable
+	Data      Map    // Cache entry data
+}
+
+type CacheMemory struct {
+	Files map[string][]byte // Cache file data by path
+	Dirty map[string]bool   // Cache files not yet written to disk
+	Lock  sync.Mutex        // Lock for concurrent access
+}
+
+const (
+	CacheVersion = 1 // Current schema version of cache files
+)
+
+var (
+	cacheMigrations = map[int]func(Map) Map{
+		0: func(m Map) Map { return m }, // Unversioned cache files share schema 1
+	}
+)
+
+var (
+	cachePrefix = []byte("encrypted:v1:") // Prefix of encrypted cache files
+)
+
+var (
+	Memory = CacheMemory{Files: map[string][]byte{}, Dirty: map[string]bool{}} // In-memory cache files
+)
+
+type CacheBatch struct {
+	Files map[string][]byte // Cache file data by path
+}
+
+var (
+	batchLock sync.Mutex // Lock for atomic batch writes
+)
+
+type CacheReport struct {
+	Workplaces int   // Number of removed workplace folders
+	Files      int   // Number of removed cache files
+	Bytes      int64 // Number of removed bytes
+}
+
 func InitCache() {
 	if HasFlag("disable-cache-folder") {
 		Args.Cache = "disabled"
@@ -28,6 +90,22 @@ func InitCache() {
 	if _, err := os.Stat(cacheFolderPath); os.IsNotExist(err) {
 		os.MkdirAll(cacheFolderPath, 0755)
 	}
+
+	// Recover interrupted batch writes
+	RecoverCache()
+
+	// Snapshot in-memory cache periodically
+	go func() {
+		for {
+			time.Sleep(snapshotInterval())
+			if Config.CacheMemory {
+				FlushCache()
+			}
+		}
+	}()
+
+	// Flush in-memory cache on fatal errors
+	log.RegisterExitHandler(FlushCache)
 }
 
 func CacheFolderPath(name string) string {
@@ -41,7 +119,606 @@ func CacheFolderPath(name string) string {
 	return filepath.Join(userCacheDir, name)
 }
 
+func DirtyCache() []string {
+	Memory.Lock.Lock()
+	defer Memory.Lock.Unlock()
+
+	// Sort dirty cache paths
+	paths := []string{}
+	for path := range Memory.Dirty {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
 func CacheDisabled() bool {
 	arg := strings.ToLower(strings.TrimSpace(Args.Cache))
 	return IsInList(arg, []string{"", "0", "off", "false", "disabled"})
 }
+
+func CacheWorkplacesPath() string {
+	return filepath.Join(Args.Cache, "workplaces")
+}
+
+func WriteCache(path string, data []byte) error {
+	if !Config.CacheMemory {
+		return os.WriteFile(path, data, 0644)
+	}
+	Memory.Lock.Lock()
+	defer Memory.Lock.Unlock()
+
+	// Keep cache file in memory
+	Memory.Files[path] = data
+	Memory.Dirty[path] = true
+
+	return nil
+}
+
+func ReadCache(path string) ([]byte, error) {
+	Memory.Lock.Lock()
+	data, ok := Memory.Files[path]
+	Memory.Lock.Unlock()
+
+	// Prefer cache file from memory
+	if ok {
+		return data, nil
+	}
+
+	return os.ReadFile(path)
+}
+
+func FlushCache() {
+	if CacheDisabled() {
+		return
+	}
+	Memory.Lock.Lock()
+	defer Memory.Lock.Unlock()
+
+	// Collect dirty cache files
+	files := make(map[string][]byte)
+	for path := range Memory.Dirty {
+		files[path] = Memory.Files[path]
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	// Write dirty cache files to disk
+	if err := writeAtomic(files); err != nil {
+		log.Warn("Error writing cache snapshot: ", err)
+		return
+	}
+	for path := range files {
+		delete(Memory.Dirty, path)
+	}
+
+	log.Debug("Write cache snapshot with ", len(files), " files")
+}
+
+func CreateCacheBatch() *CacheBatch {
+	return &CacheBatch{Files: make(map[string][]byte)}
+}
+
+func (b *CacheBatch) Add(path string, data []byte) {
+	b.Files[path] = data
+}
+
+func (b *CacheBatch) Commit() error {
+	if CacheDisabled() || len(b.Files) == 0 {
+		return nil
+	}
+
+	// Keep cache files in memory
+	if Config.CacheMemory {
+		for path, data := range b.Files {
+			WriteCache(path, data)
+		}
+		return nil
+	}
+
+	return writeAtomic(b.Files)
+}
+
+func RecoverCache() {
+	if CacheDisabled() {
+		return
+	}
+	batchLock.Lock()
+	defer batchLock.Unlock()
+
+	// Complete batch write of an existing manifest
+	if data, err := os.ReadFile(manifestPath()); err == nil {
+		paths := []string{}
+		if json.Unmarshal(data, &paths) == nil {
+			log.Warn("Recover interrupted cache write with ", len(paths), " files")
+			renameTemps(paths)
+		}
+		os.Remove(manifestPath())
+	}
+
+	// Remove temporary files of incomplete batch writes
+	filepath.WalkDir(CacheWorkplacesPath(), func(file string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && filepath.Ext(file) == ".tmp" {
+			os.Remove(file)
+		}
+		return nil
+	})
+}
+
+func writeAtomic(files map[string][]byte) error {
+	batchLock.Lock()
+	defer batchLock.Unlock()
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Write temporary cache files
+	for _, path := range paths {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path+".tmp", files[path], 0644); err != nil {
+			for _, path := range paths {
+				os.Remove(path + ".tmp")
+			}
+			return err
+		}
+	}
+
+	// Write manifest of temporary cache files
+	manifest, _ := json.Marshal(paths)
+	if err := os.WriteFile(manifestPath()+".tmp", manifest, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(manifestPath()+".tmp", manifestPath()); err != nil {
+		return err
+	}
+
+	// Replace cache files in one pass
+	renameTemps(paths)
+
+	return os.Remove(manifestPath())
+}
+
+func renameTemps(paths []string) {
+	for _, path := range paths {
+		if _, err := os.Stat(path + ".tmp"); err != nil {
+			continue
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			log.Warn("Error replacing cache file ", path)
+		}
+	}
+}
+
+func manifestPath() string {
+	return filepath.Join(Args.Cache, "manifest.json")
+}
+
+func MarshalCache(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add schema version to cache data
+	m := Map{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	m["Version"] = CacheVersion
+
+	return json.MarshalIndent(m, "", "  ")
+}
+
+func MigrateCache(path string, data []byte) ([]byte, error) {
+	m := Map{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		BackupCache(path, data, "corrupt")
+		return nil, err
+	}
+
+	// Obtain schema version of cache data
+	version := 0
+	if v, ok := m["Version"].(float64); ok {
+		version = int(v)
+	}
+	if version == CacheVersion {
+		return data, nil
+	}
+
+	// Backup cache data of unknown versions
+	if version > CacheVersion || version < 0 {
+		BackupCache(path, data, fmt.Sprintf("v%d", version))
+		return nil, fmt.Errorf("unknown cache version %d", version)
+	}
+
+	// Migrate cache data to current version
+	for ; version < CacheVersion; version++ {
+		migrate, ok := cacheMigrations[version]
+		if !ok {
+			BackupCache(path, data, fmt.Sprintf("v%d", version))
+			return nil, fmt.Errorf("missing cache migration from version %d", version)
+		}
+		m = migrate(m)
+	}
+	m["Version"] = CacheVersion
+
+	log.Debug("Migrate cache data to version ", CacheVersion, " ", path)
+
+	return json.Marshal(m)
+}
+
+func BackupCache(path string, data []byte, suffix string) {
+	backup := fmt.Sprintf("%s.%s.bak", path, suffix)
+
+	// Keep unreadable cache data for manual recovery
+	data, err := EncryptCache(data)
+	if err == nil {
+		err = os.WriteFile(backup, data, 0644)
+	}
+	if err != nil {
+		log.Warn("Error writing cache backup ", backup)
+		return
+	}
+
+	log.Warn("Backup cache data to ", backup)
+}
+
+func EncryptCache(data []byte) ([]byte, error) {
+	if len(Config.CacheKey) == 0 {
+		return data, nil
+	}
+
+	// Create cipher from user key
+	gcm, err := cacheCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// Encrypt and encode cache data
+	sealed := gcm.Seal(nonce, nonce, data, nil)
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+
+	return append(append([]byte{}, cachePrefix...), encoded...), nil
+}
+
+func DecryptCache(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, cachePrefix) {
+		return data, nil
+	}
+	if len(Config.CacheKey) == 0 {
+		return nil, fmt.Errorf("missing cache key")
+	}
+
+	// Decode encrypted cache data
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimPrefix(data, cachePrefix)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Create cipher from user key
+	gcm, err := cacheCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid cache data")
+	}
+
+	// Decrypt cache data
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func cacheCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(Config.CacheKey))
+
+	// Create aes-gcm cipher
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func snapshotInterval() time.Duration {
+	if Config.CacheSnapshot <= 0 {
+		return 300 * time.Second
+	}
+	return time.Duration(Config.CacheSnapshot) * time.Second
+}
+
+func PruneCache(keep string) CacheReport {
+	report := CacheReport{}
+	if CacheDisabled() {
+		return report
+	}
+
+	// Obtain pruning durations
+	day := 24 * time.Hour
+	workplaceTtl := time.Duration(Config.CachePruneWorkplaces) * day
+	clientTtl := time.Duration(Config.CachePruneClients) * day
+
+	// Read workplace folders
+	folders, err := os.ReadDir(CacheWorkplacesPath())
+	if err != nil {
+		return report
+	}
+
+	for _, folder := range folders {
+		if !folder.IsDir() {
+			continue
+		}
+		path := filepath.Join(CacheWorkplacesPath(), folder.Name())
+
+		// Remove workplaces not seen for a given duration
+		size, modified := folderStats(path)
+		if workplaceTtl > 0 && folder.Name() != keep && time.Since(modified) > workplaceTtl {
+			if os.RemoveAll(path) == nil {
+				log.Info("Prune workplace cache ", folder.Name())
+				report.Workplaces += 1
+				report.Bytes += size
+			}
+			continue
+		}
+
+		// Remove clients not touched for a given duration
+		if clientTtl <= 0 {
+			continue
+		}
+		filepath.WalkDir(filepath.Join(path, "clients"), func(file string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) <= clientTtl {
+				return nil
+			}
+			if os.Remove(file) == nil {
+				log.Info("Prune client cache ", file)
+				report.Files += 1
+				report.Bytes += info.Size()
+			}
+			return nil
+		})
+
+		// Remove empty client folders
+		classes, _ := os.ReadDir(filepath.Join(path, "clients"))
+		for _, class := range classes {
+			os.Remove(filepath.Join(path, "clients", class.Name()))
+		}
+	}
+
+	return report
+}
+
+func folderStats(path string) (int64, time.Time) {
+	size := int64(0)
+	modified := time.Time{}
+
+	// Obtain folder size and latest modification time
+	filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if !entry.IsDir() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+
+	return size, modified
+}
+
+func CacheEntries() []CacheEntry {
+	entries := []CacheEntry{}
+
+	// Read workplace folders
+	folders, err := os.ReadDir(CacheWorkplacesPath())
+	if err != nil {
+		return entries
+	}
+
+	for _, folder := range folders {
+		for _, typ := range []string{"clients", "workspaces"} {
+			path := filepath.Join(CacheWorkplacesPath(), folder.Name(), typ)
+
+			// Read cache entries
+			filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() || filepath.Ext(file) != ".json" {
+					return nil
+				}
+				item := CacheEntry{
+					Id:        strings.TrimSuffix(entry.Name(), ".json"),
+					Path:      file,
+					Workplace: folder.Name(),
+					Type:      typ,
+					Data:      Map{},
+				}
+
+				// Parse cache entry
+				data, err := os.ReadFile(file)
+				if err == nil {
+					data, err = DecryptCache(data)
+				}
+				if err != nil || json.Unmarshal(data, &item.Data) != nil {
+					item.Corrupt = true
+				}
+				entries = append(entries, item)
+
+				return nil
+			})
+		}
+	}
+
+	// Sort cache entries
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Workplace != entries[j].Workplace {
+			return entries[i].Workplace < entries[j].Workplace
+		}
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].Summary() < entries[j].Summary()
+	})
+
+	return entries
+}
+
+func CacheEntryGet(id string) (CacheEntry, bool) {
+	for _, entry := range CacheEntries() {
+		if entry.Id == id {
+			return entry, true
+		}
+	}
+	return CacheEntry{}, false
+}
+
+func (e CacheEntry) Summary() string {
+	if e.Corrupt {
+		return "<corrupt>"
+	}
+
+	switch e.Type {
+	case "clients":
+		latest, _ := e.Data["Latest"].(Map)
+		location, _ := latest["Location"].(Map)
+		dimensions, _ := latest["Dimensions"].(Map)
+		geometry, _ := dimensions["Geometry"].(Map)
+		return fmt.Sprintf("%v (desktop %v) %v,%v %vx%v", latest["Class"], location["Desktop"], geometry["X"], geometry["Y"], geometry["Width"], geometry["Height"])
+	case "workspaces":
+		summary := fmt.Sprintf("%v (layout %v, tiling %v)", e.Data["Name"], e.Data["Layout"], e.Data["Tiling"])
+		if alias, ok := e.Data["Alias"].(string); ok && len(alias) > 0 {
+			summary = fmt.Sprintf("%s \"%s\"", summary, alias)
+		}
+		return summary
+	}
+
+	return ""
+}
+
+func ExportCache(archive string) (int, error) {
+	count := 0
+
+	// Create archive file
+	file, err := os.Create(archive)
+	if err != nil {
+		return count, err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	// Write workplace cache files into archive
+	err = filepath.WalkDir(CacheWorkplacesPath(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		// Write archive header
+		name, err := filepath.Rel(Args.Cache, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		// Write archive content
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err = tw.Write(data); err != nil {
+			return err
+		}
+		count += 1
+
+		return nil
+	})
+
+	return count, err
+}
+
+func ImportCache(archive string) (int, error) {
+	count := 0
+
+	// Open archive file
+	file, err := os.Open(archive)
+	if err != nil {
+		return count, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return count, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	// Read workplace cache files from archive
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Validate archive paths
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(name, "workplaces"+string(filepath.Separator)) || filepath.Ext(name) != ".json" {
+			log.Warn("Skip invalid cache archive entry ", header.Name)
+			continue
+		}
+		path := filepath.Join(Args.Cache, name)
+
+		// Write cache file
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return count, err
+		}
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err = os.WriteFile(path, data, 0644); err != nil {
+			return count, err
+		}
+		os.Chtimes(path, header.ModTime, header.ModTime)
+		count += 1
+	}
+
+	return count, nil
+}
@@ -15,31 +15,83 @@ import (
 )
 
 var (
-	Config Configuration // Decoded config values
+	Config          Configuration // Decoded config values
+	configCallbacks []func()      // Callback functions on config file updates
 )
 
 type Configuration struct {
-	TilingEnabled     bool              `toml:"tiling_enabled"`      // Tile windows on startup
-	TilingLayout      string            `toml:"tiling_layout"`       // Initial tiling layout
-	TilingCycle       []string          `toml:"tiling_cycle"`        // Cycle layout order
-	TilingGui         int               `toml:"tiling_gui"`          // Time duration of gui
-	TilingIcon        [][]string        `toml:"tiling_icon"`         // Menu entries of systray
-	WindowIgnore      [][]string        `toml:"window_ignore"`       // Regex to ignore windows
-	WindowMastersMax  int               `toml:"window_masters_max"`  // Maximum number of allowed masters
-	WindowSlavesMax   int               `toml:"window_slaves_max"`   // Maximum number of allowed slaves
-	WindowGapSize     int               `toml:"window_gap_size"`     // Gap size between windows
-	WindowFocusDelay  int               `toml:"window_focus_delay"`  // Window focus delay when hovered
-	WindowDecoration  bool              `toml:"window_decoration"`   // Show window decorations
-	ProportionStep    float64           `toml:"proportion_step"`     // Master-slave area step size proportion
-	ProportionMin     float64           `toml:"proportion_min"`      // Window size minimum proportion
-	EdgeMargin        []int             `toml:"edge_margin"`         // Margin values of tiling area
-	EdgeMarginPrimary []int             `toml:"edge_margin_primary"` // Margin values of primary tiling area
-	EdgeCornerSize    int               `toml:"edge_corner_size"`    // Size of square defining edge corners
-	EdgeCenterSize    int               `toml:"edge_center_size"`    // Length of rectangle defining edge centers
-	Colors            map[string][]int  `toml:"colors"`              // List of color values for gui elements
-	Keys              map[string]string `toml:"keys"`                // Event bindings for keyboard shortcuts
-	Corners           map[string]string `toml:"corners"`             // Event bindings for hot-corner actions
-	Systray           map[string]string `toml:"systray"`             // Event bindings for systray icon
+	TilingEnabled            bool               `toml:"tiling_enabled"`             // Tile windows on startup
+	TilingLayout             string             `toml:"tiling_layout"`              // Initial tiling layout
+	TilingCycle              []string           `toml:"tiling_cycle"`               // Cycle layout order
+	TilingOutputs            []string           `toml:"tiling_outputs"`             // Outputs where tiling is allowed
+	TilingSplit              [][]string         `toml:"tiling_split"`               // Boundaries of virtual screens per output
+	TilingGui                int                `toml:"tiling_gui"`                 // Time duration of gui
+	TilingPause              int                `toml:"tiling_pause"`               // Time duration of tiling pause
+	TilingBuffer             int                `toml:"tiling_buffer"`              // Buffer size of event channels
+	TilingCoalesce           int                `toml:"tiling_coalesce"`            // Time duration to coalesce root events
+	TilingRepeat             int                `toml:"tiling_repeat"`              // Time duration to batch repeated actions
+	TilingIcon               [][]string         `toml:"tiling_icon"`                // Menu entries of systray
+	TilingIndicator          string             `toml:"tiling_indicator"`           // Screen corner of layout indicator
+	WindowIgnore             [][]string         `toml:"window_ignore"`              // Regex to ignore windows
+	WindowPlacement          [][]string         `toml:"window_placement"`           // Regex to place windows on startup
+	WindowPlacementTime      int                `toml:"window_placement_time"`      // Time duration of startup window placement
+	WindowCategory           [][]string         `toml:"window_category"`            // Regex to send new windows to desktops
+	WindowTitle              [][]string         `toml:"window_title"`               // Regex to float or move windows on title changes
+	WindowMinSize            [][]string         `toml:"window_min_size"`            // Regex to set minimum tile sizes
+	WindowSettle             [][]string         `toml:"window_settle"`              // Regex to delay tracking of new windows
+	WindowQuirks             [][]string         `toml:"window_quirks"`              // Regex to override built-in quirk profiles
+	WindowMastersMax         int                `toml:"window_masters_max"`         // Maximum number of allowed masters
+	WindowSlavesMax          int                `toml:"window_slaves_max"`          // Maximum number of allowed slaves
+	WindowGapSize            int                `toml:"window_gap_size"`            // Gap size between windows
+	WindowScale              bool               `toml:"window_scale"`               // Scale gaps and margins by screen dpi
+	WindowFocusDelay         int                `toml:"window_focus_delay"`         // Window focus delay when hovered
+	WindowFloatDrop          bool               `toml:"window_float_drop"`          // Float windows dropped outside of any tile
+	WindowDrop               string             `toml:"window_drop"`                // Behavior of windows dropped onto another window
+	WindowSwapTimeout        int                `toml:"window_swap_timeout"`        // Time duration of swap target selection
+	WindowAnimation          int                `toml:"window_animation"`           // Time duration of move/resize animations
+	WindowAnimationSteps     int                `toml:"window_animation_steps"`     // Number of move/resize animation steps
+	WindowDecoration         bool               `toml:"window_decoration"`          // Show window decorations
+	WindowDecorationOverride [][]string         `toml:"window_decoration_override"` // Regex to keep or strip window decorations
+	WindowStacking           [][]string         `toml:"window_stacking"`            // Stacking order of masters and slaves per layout
+	GameMode                 bool               `toml:"game_mode"`                  // Suspend tiling for focused games
+	GameClasses              []string           `toml:"game_classes"`               // Regex to detect game windows
+	DesktopBackAndForth      bool               `toml:"desktop_back_and_forth"`     // Switch back when switching to the current desktop
+	DesktopFocus             bool               `toml:"desktop_focus"`              // Re-activate last focused window on desktop switch
+	ProportionStep           float64            `toml:"proportion_step"`            // Master-slave area step size proportion
+	ProportionMin            float64            `toml:"proportion_min"`             // Window size minimum proportion
+	ProportionSnap           float64            `toml:"proportion_snap"`            // Tolerance to snap master-slave proportions
+	ProportionStops          []float64          `toml:"proportion_stops"`           // Master-slave proportions to snap to
+	EdgeMargin               []int              `toml:"edge_margin"`                // Margin values of tiling area
+	EdgeMarginPrimary        []int              `toml:"edge_margin_primary"`        // Margin values of primary tiling area
+	EdgeReserve              [][]string         `toml:"edge_reserve"`               // Reserved space per output and edge
+	EdgeMaxWidth             int                `toml:"edge_max_width"`             // Maximum content width for few windows
+	EdgeMaxWidthClients      int                `toml:"edge_max_width_clients"`     // Maximum number of windows with limited content width
+	EdgeStrutDelay           int                `toml:"edge_strut_delay"`           // Time duration to debounce panel strut changes
+	EdgeStrutMax             bool               `toml:"edge_strut_max"`             // Always reserve the maximum panel struts
+	EdgeCornerSize           int                `toml:"edge_corner_size"`           // Size of square defining edge corners
+	EdgeCenterSize           int                `toml:"edge_center_size"`           // Length of rectangle defining edge centers
+	CachePruneClients        int                `toml:"cache_prune_clients"`        // Time duration of unused client cache
+	CachePruneWorkplaces     int                `toml:"cache_prune_workplaces"`     // Time duration of unused workplace cache
+	CacheNames               bool               `toml:"cache_names"`                // Store window titles in cache
+	CacheKey                 string             `toml:"cache_key"`                  // User key to encrypt cache files
+	CacheJournal             bool               `toml:"cache_journal"`              // Journal recent workspace changes
+	CacheMemory              bool               `toml:"cache_memory"`               // Keep cache in memory and write snapshots
+	CacheSnapshot            int                `toml:"cache_snapshot"`             // Time interval of cache snapshots
+	CacheWriteDelay          int                `toml:"cache_write_delay"`          // Delay of cache writes after changes
+	CacheWriteMax            int                `toml:"cache_write_max"`            // Maximum delay of pending cache writes
+	CacheStats               bool               `toml:"cache_stats"`                // Store usage statistics in cache
+	CacheAliases             [][]string         `toml:"cache_aliases"`              // Shared cache folders of display setups
+	PowerProfile             string             `toml:"power_profile"`              // Behavior profile for power supply
+	PowerIdle                int                `toml:"power_idle"`                 // Idle time to defer background work
+	LogSize                  int                `toml:"log_size"`                   // Log file size before rotation
+	LogFiles                 int                `toml:"log_files"`                  // Number of rotated log files
+	Scales                   map[string]float64 `toml:"scales"`                     // List of forced scale values per output
+	Colors                   map[string][]int   `toml:"colors"`                     // List of color values for gui elements
+	Keys                     map[string]string  `toml:"keys"`                       // Event bindings for keyboard shortcuts
+	Corners                  map[string]string  `toml:"corners"`                    // Event bindings for hot-corner actions
+	Systray                  map[string]string  `toml:"systray"`                    // Event bindings for systray icon
+	Levels                   map[string]string  `toml:"levels"`                     // Log levels per subsystem
+	Capabilities             map[string]bool    `toml:"capabilities"`               // Overrides of window manager capabilities
 }
 
 func InitConfig() {
@@ -58,10 +110,28 @@ func InitConfig() {
 	// Read config file into memory
 	readConfig(Args.Config, true)
 
+	// Read runtime ignore list
+	InitIgnore()
+
 	// Config file system watcher
 	watchConfig(Args.Config)
 }
 
+func LoadConfig() {
+
+	// Decode default config into struct
+	toml.Decode(string(File.Toml), &Config)
+
+	// Decode config file into struct
+	if _, err := os.Stat(Args.Config); err == nil {
+		readConfig(Args.Config, false)
+	}
+}
+
+func OnConfigUpdate(fun func()) {
+	configCallbacks = append(configCallbacks, fun)
+}
+
 func ConfigFolderPath(name string) string {
 
 	// Obtain user config directory
@@ -95,6 +165,12 @@ func readConfig(configFilePath string, initial bool) {
 		}
 	}
 
+	// Decode managed key bindings into struct
+	readKeys()
+
+	// Update subsystem log levels
+	UpdateLogLevel()
+
 	// Print shortcut infos
 	if initial {
 		keys, _ := json.MarshalIndent(Config.Keys, "", "  ")
@@ -127,6 +203,9 @@ func watchConfig(configFilePath string) {
 				}
 				if event.Has(fsnotify.Write) {
 					readConfig(configFilePath, false)
+					for _, fun := range configCallbacks {
+						fun()
+					}
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
@@ -0,0 +1,118 @@
+package common
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"encoding/json"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Ignore IgnoreInfo // Runtime ignored window classes
+)
+
+type IgnoreInfo struct {
+	Classes []string   // Ignored window classes (lower case)
+	Lock    sync.Mutex `json:"-"` // Lock for concurrent list access
+}
+
+func InitIgnore() {
+	if CacheDisabled() {
+		return
+	}
+	Ignore.Lock.Lock()
+	defer Ignore.Lock.Unlock()
+
+	// Read runtime ignore list
+	data, err := os.ReadFile(IgnoreFilePath())
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &Ignore.Classes); err != nil {
+		log.Warn("Error reading ignore file: ", err)
+		return
+	}
+
+	log.Info("Ignore window classes ", Ignore.Classes)
+}
+
+func IgnoreFilePath() string {
+	return filepath.Join(Args.Cache, "ignore.json")
+}
+
+func IgnoreClass(class string) bool {
+	Ignore.Lock.Lock()
+	defer Ignore.Lock.Unlock()
+
+	// Add class to runtime ignore list
+	class = strings.ToLower(class)
+	if len(class) == 0 || IsInList(class, Ignore.Classes) {
+		return false
+	}
+	Ignore.Classes = append(Ignore.Classes, class)
+	sort.Strings(Ignore.Classes)
+
+	// Write runtime ignore list
+	writeIgnore()
+
+	log.Info("Ignore window class from now on [", class, "]")
+
+	return true
+}
+
+func UnignoreClass(class string) bool {
+	Ignore.Lock.Lock()
+	defer Ignore.Lock.Unlock()
+
+	// Remove class from runtime ignore list
+	class = strings.ToLower(class)
+	classes := []string{}
+	for _, c := range Ignore.Classes {
+		if c != class {
+			classes = append(classes, c)
+		}
+	}
+	if len(classes) == len(Ignore.Classes) {
+		return false
+	}
+	Ignore.Classes = classes
+
+	// Write runtime ignore list
+	writeIgnore()
+
+	log.Info("Track window class again [", class, "]")
+
+	return true
+}
+
+func IsIgnoredClass(class string) bool {
+	Ignore.Lock.Lock()
+	defer Ignore.Lock.Unlock()
+
+	return IsInList(strings.ToLower(class), Ignore.Classes)
+}
+
+func writeIgnore() {
+	if CacheDisabled() {
+		return
+	}
+
+	// Write runtime ignore list
+	data, err := json.MarshalIndent(Ignore.Classes, "", "  ")
+	if err != nil {
+		return
+	}
+	path := IgnoreFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn("Error creating ignore folder: ", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn("Error writing ignore file: ", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"encoding/json"
 	"net/http"
@@ -23,10 +24,11 @@ var (
 )
 
 type ProcessInfo struct {
-	Id   int            // Process id
-	Path string         // Process path
-	User *user.User     // Process user
-	Host *host.InfoStat // Process host
+	Id      int            // Process id
+	Path    string         // Process path
+	User    *user.User     // Process user
+	Host    *host.InfoStat // Process host
+	Started int64          // Process start timestamp
 }
 
 type BuildInfo struct {
@@ -59,7 +61,8 @@ func InitInfo(name, target, version, commit, date, source, flags string) {
 
 	// Process information
 	Process = ProcessInfo{
-		Id: os.Getpid(),
+		Id:      os.Getpid(),
+		Started: time.Now().UnixMilli(),
 	}
 
 	// Process path information
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"bytes"
+	"os"
+
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type KeysFragment struct {
+	Keys map[string]string `toml:"keys"` // Managed key bindings
+}
+
+func KeysFilePath() string {
+	return filepath.Join(Args.Cache, "keys.toml")
+}
+
+func PersistKey(action string, key string) bool {
+	if CacheDisabled() {
+		log.Warn("Error persisting key binding, cache is disabled")
+		return false
+	}
+	fragment := KeysFragment{Keys: map[string]string{}}
+
+	// Read managed key bindings
+	if _, err := os.Stat(KeysFilePath()); err == nil {
+		if _, err := toml.DecodeFile(KeysFilePath(), &fragment); err != nil {
+			log.Warn("Error reading keys file: ", err)
+			return false
+		}
+	}
+	fragment.Keys[action] = key
+
+	// Write managed key bindings
+	data := bytes.NewBufferString("# Key bindings managed via \"" + Build.Name + " keys add|remove -persist\", applied after config.toml.\n\n")
+	if err := toml.NewEncoder(data).Encode(fragment); err != nil {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(KeysFilePath()), 0755); err != nil {
+		log.Warn("Error creating keys folder: ", err)
+		return false
+	}
+	if err := os.WriteFile(KeysFilePath(), data.Bytes(), 0644); err != nil {
+		log.Warn("Error writing keys file: ", err)
+		return false
+	}
+
+	log.Info("Persist key binding ", action, " = \"", key, "\"")
+
+	return true
+}
+
+func readKeys() {
+	if CacheDisabled() {
+		return
+	}
+	if _, err := os.Stat(KeysFilePath()); err != nil {
+		return
+	}
+
+	// Decode managed key bindings into struct
+	if _, err := toml.DecodeFile(KeysFilePath(), &Config); err != nil {
+		log.Warn("Error reading keys file: ", err)
+	}
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type LogFormatter struct {
+	Formatter log.Formatter // Underlying text or json formatter
+}
+
+type LogFile struct {
+	Path string     // Log file path
+	File *os.File   // Opened log file
+	Size int64      // Current log file size
+	Lock sync.Mutex // Lock for concurrent writes
+}
+
+var (
+	logLevel      = log.WarnLevel // Global log level from arguments
+	logSubsystems = map[string]string{
+		"desktop": "tracker",
+		"layout":  "tracker",
+		"store":   "store",
+		"input":   "input",
+		"ui":      "ui",
+	} // Subsystem names per package
+)
+
+func InitLogging(level log.Level, format string) {
+	logLevel = level
+
+	// Init log formatter
+	var formatter log.Formatter = &log.TextFormatter{ForceColors: true, FullTimestamp: true}
+	if format == "json" {
+		formatter = &log.JSONFormatter{}
+	}
+	log.SetFormatter(&LogFormatter{Formatter: formatter})
+	UpdateLogLevel()
+}
+
+func UpdateLogLevel() {
+	level := logLevel
+
+	// Enable most verbose subsystem level
+	overrides := false
+	for subsystem, value := range Config.Levels {
+		overrides = overrides || len(value) > 0
+		if sublevel := LogLevel(subsystem); sublevel > level {
+			level = sublevel
+		}
+	}
+	log.SetLevel(level)
+
+	// Report callers to obtain subsystems (at -vv or with subsystem levels only)
+	// Report callers to obtain subsystems (at -vv, with subsystem levels or for journald fields)
+}
+
+func LogLevel(subsystem string) log.Level {
+	level, err := log.ParseLevel(Config.Levels[subsystem])
+	if err != nil {
+		return logLevel
+	}
+	return level
+}
+
+func LogEnabled(entry *log.Entry) bool {
+	return entry.Level <= LogLevel(logSubsystem(entry))
+}
+
+func logSubsystem(entry *log.Entry) string {
+
+	// Map package name to subsystem of log levels
+	if subsystem, ok := logSubsystems[Subsystem(entry)]; ok {
+		return subsystem
+	}
+
+	return "main"
+}
+
+func (f *LogFormatter) Format(entry *log.Entry) ([]byte, error) {
+	if !LogEnabled(entry) {
+		return nil, nil
+	}
+
+	// Replace caller with subsystem field
+	formatted := *entry
+	formatted.Data = log.Fields{"subsystem": Subsystem(entry)}
+	for key, value := range entry.Data {
+		formatted.Data[key] = value
+	}
+	formatted.Caller = nil
+
+	return f.Formatter.Format(&formatted)
+}
+
+func CreateLogFile(path string) (*LogFile, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &LogFile{Path: path, File: file, Size: info.Size()}, nil
+}
+
+func (l *LogFile) Write(data []byte) (int, error) {
+	l.Lock.Lock()
+	defer l.Lock.Unlock()
+
+	// Rotate oversized log file
+	limit := int64(Config.LogSize) * 1024 * 1024
+	if limit > 0 && l.Size+int64(len(data)) > limit {
+		l.rotate()
+	}
+
+	n, err := l.File.Write(data)
+	l.Size += int64(n)
+
+	return n, err
+}
+
+func (l *LogFile) Close() error {
+	l.Lock.Lock()
+	defer l.Lock.Unlock()
+
+	return l.File.Close()
+}
+
+func (l *LogFile) rotate() {
+	l.File.Close()
+
+	// Shift rotated log files
+	files := max(Config.LogFiles, 0)
+	os.Remove(fmt.Sprintf("%s.%d", l.Path, files))
+	for i := files - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.Path, i), fmt.Sprintf("%s.%d", l.Path, i+1))
+	}
+	if files > 0 {
+		os.Rename(l.Path, fmt.Sprintf("%s.1", l.Path))
+	}
+
+	// Reopen truncated log file
+	file, err := os.OpenFile(l.Path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		file, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0644)
+	}
+	l.File = file
+	l.Size = 0
+}
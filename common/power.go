@@ -0,0 +1,114 @@
+package common
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	power     PowerInfo  // Power supply information
+	powerLock sync.Mutex // Lock for concurrent power access
+)
+
+type PowerInfo struct {
+	Battery bool    // Running on battery power
+	Profile Profile // Active behavior profile
+}
+
+type Profile struct {
+	Name         string // Profile name
+	WriteDelay   int    // Cache write delay [ms]
+	PollInterval int    // Pointer poll interval [ms]
+	HoverFocus   bool   // Focus windows on hover
+	Animations   bool   // Animate window move/resize
+}
+
+var profiles = map[string]Profile{
+	"performance": {
+		Name:         "performance",
+		WriteDelay:   0,
+		PollInterval: 100,
+		HoverFocus:   true,
+		Animations:   true,
+	},
+	"powersave": {
+		Name:         "powersave",
+		WriteDelay:   5000,
+		PollInterval: 250,
+		HoverFocus:   false,
+		Animations:   false,
+	},
+}
+
+func InitPower() {
+
+	// Init power profile
+	updatePower()
+
+	// Power supply watcher
+	go func() {
+		for range time.Tick(30 * time.Second) {
+			updatePower()
+		}
+	}()
+}
+
+func Power() PowerInfo {
+	powerLock.Lock()
+	defer powerLock.Unlock()
+
+	return power
+}
+
+func OnBattery() bool {
+	mains, online := false, false
+
+	// Read power supply states
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	for _, supply := range supplies {
+		typ, err := os.ReadFile(filepath.Join(supply, "type"))
+		if err != nil || strings.TrimSpace(string(typ)) != "Mains" {
+			continue
+		}
+		mains = true
+
+		// Check if mains supply is online
+		state, err := os.ReadFile(filepath.Join(supply, "online"))
+		if err == nil && strings.TrimSpace(string(state)) == "1" {
+			online = true
+		}
+	}
+
+	return mains && !online
+}
+
+func updatePower() {
+	battery := OnBattery()
+
+	// Obtain profile name
+	name := strings.ToLower(Config.PowerProfile)
+	if _, ok := profiles[name]; !ok {
+		name = "performance"
+		if battery {
+			name = "powersave"
+		}
+	}
+
+	// Switch power profile
+	powerLock.Lock()
+	defer powerLock.Unlock()
+
+	if power.Profile.Name != name {
+		log.Info("Switch power profile to ", name, " [battery=", battery, "]")
+	}
+	power = PowerInfo{
+		Battery: battery,
+		Profile: profiles[name],
+	}
+}
@@ -0,0 +1,185 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"encoding/binary"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Systemd SystemdInfo // Systemd supervision information
+)
+
+type SystemdInfo struct {
+	Notify   string        // Notification socket path
+	Watchdog time.Duration // Watchdog timeout interval
+	Journal  bool          // Logging to journald
+}
+
+type JournalHook struct {
+	Socket string // Journald socket path
+}
+
+var (
+	journalSocket     = "/run/systemd/journal/socket" // Native journald protocol socket
+	journalPriorities = map[log.Level]int{
+		log.PanicLevel: 2,
+		log.FatalLevel: 2,
+		log.ErrorLevel: 3,
+		log.WarnLevel:  4,
+		log.InfoLevel:  6,
+		log.DebugLevel: 7,
+		log.TraceLevel: 7,
+	} // Syslog priorities per log level
+)
+
+func InitSystemd() {
+	Systemd = SystemdInfo{Notify: os.Getenv("NOTIFY_SOCKET")}
+
+	// Obtain watchdog interval
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	pid, _ := strconv.Atoi(os.Getenv("WATCHDOG_PID"))
+	if err == nil && usec > 0 && (pid == 0 || pid == os.Getpid()) {
+		Systemd.Watchdog = time.Duration(usec) * time.Microsecond
+	}
+
+	// Check journald stream
+	if _, err := os.Stat(journalSocket); err == nil && len(os.Getenv("JOURNAL_STREAM")) > 0 {
+		Systemd.Journal = true
+	}
+}
+
+func NotifySystemd(state string) bool {
+	if len(Systemd.Notify) == 0 {
+		return false
+	}
+
+	// Resolve abstract socket namespace
+	socket := Systemd.Notify
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	// Send state notification
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		log.Warn("Error connecting to systemd: ", err)
+		return false
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		log.Warn("Error notifying systemd: ", err)
+		return false
+	}
+
+	return true
+}
+
+func WatchSystemd(alive func(time.Duration) bool) {
+	if Systemd.Watchdog == 0 {
+		return
+	}
+
+	// Ping watchdog while alive
+	interval := Systemd.Watchdog / 2
+	go func() {
+		for range time.Tick(interval) {
+			if !alive(interval) {
+				log.Warn("Event loop unresponsive, skip watchdog notification")
+				continue
+			}
+			NotifySystemd("WATCHDOG=1")
+		}
+	}()
+}
+
+func Subsystem(entry *log.Entry) string {
+	if !entry.HasCaller() {
+		return "main"
+	}
+
+	// Obtain package name from caller function
+	function := entry.Caller.Function
+	function = function[strings.LastIndex(function, "/")+1:]
+
+	return strings.Split(function, ".")[0]
+}
+
+func (h *JournalHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *JournalHook) Fire(entry *log.Entry) error {
+	var data bytes.Buffer
+	if !LogEnabled(entry) {
+		return nil
+	}
+
+	// Journal standard fields
+	fields := map[string]string{
+		"MESSAGE":           entry.Message,
+		"PRIORITY":          strconv.Itoa(journalPriorities[entry.Level]),
+		"SYSLOG_IDENTIFIER": Build.Name,
+		"SUBSYSTEM":         Subsystem(entry),
+	}
+	if entry.HasCaller() {
+		fields["CODE_FILE"] = entry.Caller.File
+		fields["CODE_LINE"] = strconv.Itoa(entry.Caller.Line)
+		fields["CODE_FUNC"] = entry.Caller.Function
+	}
+
+	// Journal custom fields
+	for key, value := range entry.Data {
+		fields[journalField(key)] = fmt.Sprint(value)
+	}
+
+	// Serialize fields in native protocol
+	for key, value := range fields {
+		if !strings.Contains(value, "\n") {
+			data.WriteString(key + "=" + value + "\n")
+			continue
+		}
+		data.WriteString(key + "\n")
+		binary.Write(&data, binary.LittleEndian, uint64(len(value)))
+		data.WriteString(value + "\n")
+	}
+
+	// Send journal entry
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: h.Socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(data.Bytes())
+
+	return err
+}
+
+func CreateJournalHook() *JournalHook {
+	return &JournalHook{Socket: journalSocket}
+}
+
+func journalField(key string) string {
+	field := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+
+	return strings.TrimLeft(field, "_0123456789")
+}
@@ -142,3 +142,7 @@ func MinInt(a int, b int) int {
 func MaxInt(a int, b int) int {
 	return int(math.Max(float64(a), float64(b)))
 }
+
+func AbsInt(a int) int {
+	return int(math.Abs(float64(a)))
+}
@@ -0,0 +1,53 @@
+package desktop
+
+import (
+	"time"
+)
+
+type Audit struct {
+	Entries []AuditEntry // Recently executed actions (oldest first)
+	Maximum int          // Maximum number of stored actions
+}
+
+type AuditEntry struct {
+	Time      int64  // Action execution timestamp
+	Source    string // Action source (keybinding | corner | dbus | systray | signal | internal)
+	Action    string // Action name with arguments
+	Workspace string // Affected workspace name
+	Success   bool   // Action was executed successfully
+}
+
+func CreateAudit(max int) *Audit {
+	return &Audit{
+		Entries: make([]AuditEntry, 0),
+		Maximum: max,
+	}
+}
+
+func (a *Audit) Push(source string, action string, ws *Workspace, success bool) {
+	entry := AuditEntry{
+		Time:      time.Now().UnixMilli(),
+		Source:    source,
+		Action:    action,
+		Workspace: ws.Name,
+		Success:   success,
+	}
+
+	// Append entry and drop oldest entries
+	a.Entries = append(a.Entries, entry)
+	if len(a.Entries) > a.Maximum {
+		a.Entries = a.Entries[len(a.Entries)-a.Maximum:]
+	}
+}
+
+func (a *Audit) Latest(count int) []AuditEntry {
+	if count <= 0 || count > len(a.Entries) {
+		count = len(a.Entries)
+	}
+
+	// Copy most recent entries
+	entries := make([]AuditEntry, count)
+	copy(entries, a.Entries[len(a.Entries)-count:])
+
+	return entries
+}
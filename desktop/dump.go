@@ -0,0 +1,91 @@
+package desktop
+
+import (
+	"sort"
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+type Dump struct {
+	Time       int64               // Dump creation timestamp
+	Build      string              // Build summary
+	Workplace  *store.XWorkplace   // Workplace displays and desktops
+	Windows    *store.XWindows     // Active and stacked windows
+	Clients    []*store.Client     // Tracked clients with geometries and flags
+	Workspaces []*Workspace        // Workspaces with layouts and proportions
+	Handlers   map[string]*Handler // Active event handlers
+	Deferred   *Deferred           // Deferred work while idle
+	Audit      []AuditEntry        // Recently executed actions
+	Writes     DumpWrites          // Pending cache writes
+	Channels   DumpChannels        // Channel queue sizes
+}
+
+type DumpWrites struct {
+	Scheduled bool     // Delayed cache write is scheduled
+	Dirty     []string // In-memory cache files not yet written to disk
+}
+
+type DumpChannels struct {
+	Event   int  // Queued events
+	Action  int  // Queued actions
+	Work    int  // Queued state mutations
+	Dropped uint // Events dropped on overflow
+}
+
+func (tr *Tracker) Dump() *Dump {
+	dump := &Dump{
+		Time:      time.Now().UnixMilli(),
+		Build:     common.Build.Summary,
+		Workplace: store.Workplace,
+		Windows:   store.Windows,
+		Handlers: map[string]*Handler{
+			"KeyboardMove": tr.Handlers.KeyboardMove,
+			"ResizeClient": tr.Handlers.ResizeClient,
+			"MoveClient":   tr.Handlers.MoveClient,
+			"SwapClient":   tr.Handlers.SwapClient,
+			"SwapScreen":   tr.Handlers.SwapScreen,
+		},
+		Deferred: tr.Handlers.Deferred,
+		Audit:    tr.Audit.Latest(0),
+		Writes: DumpWrites{
+			Scheduled: tr.Handlers.Pending,
+			Dirty:     common.DirtyCache(),
+		},
+		Channels: DumpChannels{
+			Event:   len(tr.Channels.Event),
+			Action:  len(tr.Channels.Action),
+			Work:    tr.Pending(),
+			Dropped: tr.Channels.Dropped,
+		},
+	}
+
+	// Sort clients by window id
+	for _, c := range tr.Clients {
+
+		// Omit window titles
+		if !common.Config.CacheNames {
+			latest := *c.Latest
+			latest.Name = ""
+			copied := *c
+			copied.Latest = &latest
+			c = &copied
+		}
+		dump.Clients = append(dump.Clients, c)
+	}
+	sort.Slice(dump.Clients, func(i, j int) bool {
+		return dump.Clients[i].Window.Id < dump.Clients[j].Window.Id
+	})
+
+	// Sort workspaces by location
+	for _, ws := range tr.Workspaces {
+		dump.Workspaces = append(dump.Workspaces, ws)
+	}
+	sort.Slice(dump.Workspaces, func(i, j int) bool {
+		a, b := dump.Workspaces[i].Location, dump.Workspaces[j].Location
+		return a.Desktop < b.Desktop || (a.Desktop == b.Desktop && a.Screen < b.Screen)
+	})
+
+	return dump
+}
@@ -0,0 +1,164 @@
+package desktop
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"time"
+
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Journal struct {
+	Latest map[string][]byte // Latest journaled state per workspace
+}
+
+type JournalEntry struct {
+	Time      int64           // Journal entry timestamp
+	Workspace json.RawMessage // Journaled workspace state
+}
+
+var (
+	journal     = Journal{Latest: map[string][]byte{}} // Journal of recent workspace states
+	journalSize = int64(64 * 1024)                     // Maximum journal size before compaction
+)
+
+func (ws *Workspace) Journal() {
+	if !journalEnabled() {
+		return
+	}
+
+	// Parse workspace state
+	data, err := json.Marshal(ws)
+	if err != nil {
+		return
+	}
+
+	// Skip unchanged workspace states
+	if bytes.Equal(journal.Latest[ws.Name], data) {
+		return
+	}
+	journal.Latest[ws.Name] = data
+
+	// Append journal entry
+	path := JournalPath()
+	entry, _ := json.Marshal(JournalEntry{Time: time.Now().UnixMilli(), Workspace: data})
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn("Error writing journal [", ws.Name, "]")
+		return
+	}
+	file.Write(append(entry, '\n'))
+	file.Close()
+
+	// Compact oversized journal
+	if info, err := os.Stat(path); err == nil && info.Size() > journalSize {
+		compactJournal(path)
+	}
+}
+
+func (ws *Workspace) Replay() *Workspace {
+	if !journalEnabled() {
+		return nil
+	}
+
+	// Obtain workspace cache modification time
+	cache := ws.Cache()
+	modified := int64(0)
+	if info, err := os.Stat(filepath.Join(cache.Folder, cache.Name)); err == nil {
+		modified = info.ModTime().UnixMilli()
+	}
+
+	// Find latest journal entry newer than cache
+	var replayed *Workspace
+	for _, entry := range readJournal(JournalPath()) {
+		if entry.Time <= modified {
+			continue
+		}
+		journaled := &Workspace{Layouts: CreateLayouts(ws.Location)}
+		if json.Unmarshal(entry.Workspace, &journaled) != nil || journaled.Name != ws.Name {
+			continue
+		}
+		replayed = journaled
+	}
+
+	if replayed != nil {
+		log.Info("Replay workspace state from journal [", ws.Name, "]")
+	}
+
+	return replayed
+}
+
+func TruncateJournal() {
+	if common.CacheDisabled() || common.Config.CacheMemory {
+		return
+	}
+	// Remove journal after cache was written
+	os.Remove(JournalPath())
+}
+
+func JournalPath() string {
+	return filepath.Join(common.CacheWorkplacesPath(), store.Workplace.Displays.Name, "journal.jsonl")
+}
+
+func journalEnabled() bool {
+	return common.Config.CacheJournal && !common.Config.CacheMemory && !common.CacheDisabled()
+}
+
+func readJournal(path string) []JournalEntry {
+	entries := []JournalEntry{}
+
+	// Open journal file
+	file, err := os.Open(path)
+	if err != nil {
+		return entries
+	}
+	defer file.Close()
+
+	// Parse journal entries, skip incomplete lines
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(journalSize))
+	for scanner.Scan() {
+		entry := JournalEntry{}
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func compactJournal(path string) {
+
+	// Keep latest entry per workspace
+	latest := make(map[string]JournalEntry)
+	order := []string{}
+	for _, entry := range readJournal(path) {
+		named := struct{ Name string }{}
+		if json.Unmarshal(entry.Workspace, &named) != nil {
+			continue
+		}
+		if _, ok := latest[named.Name]; !ok {
+			order = append(order, named.Name)
+		}
+		latest[named.Name] = entry
+	}
+
+	// Rewrite journal file
+	data := []byte{}
+	for _, name := range order {
+		line, _ := json.Marshal(latest[name])
+		data = append(append(data, line...), '\n')
+	}
+	temp := path + ".tmp"
+	if os.WriteFile(temp, data, 0644) == nil {
+		os.Rename(temp, path)
+	}
+}
@@ -11,6 +11,11 @@ type Layout interface {
 	RemoveClient(c *store.Client)
 	MakeMaster(c *store.Client)
 	SwapClient(c1 *store.Client, c2 *store.Client)
+	SwapMaster(c *store.Client) bool
+	ShiftClient(c *store.Client, offset int) bool
+	CycleClients() bool
+	PromoteClient(c *store.Client) bool
+	DemoteClient(c *store.Client) bool
 	ActiveClient() *store.Client
 	NextClient() *store.Client
 	PreviousClient() *store.Client
@@ -20,6 +25,9 @@ type Layout interface {
 	DecreaseSlave()
 	IncreaseProportion()
 	DecreaseProportion()
+	SetProportion(proportion float64) bool
+	EqualizeProportions()
+	ResetProportions()
 	UpdateProportions(c *store.Client, d *store.Directions)
 	GetManager() *store.Manager
 	GetName() string
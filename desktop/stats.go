@@ -0,0 +1,167 @@
+package desktop
+
+import (
+	"time"
+
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Stats struct {
+	Since      int64                      // Statistics start timestamp
+	Workspaces map[string]*WorkspaceStats // Usage statistics per workspace
+	Clients    map[string]*ClientStats    // Usage statistics per window class
+	focus      statsSample                // Latest focused client sample
+	layouts    map[string]statsSample     // Latest active layout sample per workspace
+}
+
+type WorkspaceStats struct {
+	Focus   int64            // Focus time of clients [ms]
+	Tiles   uint             // Number of re-tiles
+	Layouts map[string]int64 // Residency time per layout [ms]
+}
+
+type ClientStats struct {
+	Focus   int64 // Focus time [ms]
+	Focused uint  // Number of focus changes
+}
+
+type statsSample struct {
+	Name      string        // Sampled layout name or window class
+	Window    xproto.Window // Sampled window id
+	Workspace string        // Sampled workspace name
+	Time      time.Time     // Sample timestamp
+}
+
+func CreateStats() *Stats {
+	stats := &Stats{
+		Since:      time.Now().UnixMilli(),
+		Workspaces: map[string]*WorkspaceStats{},
+		Clients:    map[string]*ClientStats{},
+		layouts:    map[string]statsSample{},
+	}
+
+	// Read persisted statistics
+	if !common.CacheDisabled() && common.Config.CacheStats {
+		data, err := common.ReadCache(StatsPath())
+		if err == nil {
+			if err := json.Unmarshal(data, stats); err != nil {
+				log.Warn("Error reading statistics: ", err)
+			}
+		}
+	}
+
+	return stats
+}
+
+func StatsPath() string {
+	return filepath.Join(common.Args.Cache, "stats.json")
+}
+
+func (tr *Tracker) Statistics() *Stats {
+	now := time.Now()
+
+	// Account running samples until now
+	tr.Stats.sampleFocus(tr.Stats.focus.Name, tr.Stats.focus.Window, tr.Stats.focus.Workspace, now)
+	for name, sample := range tr.Stats.layouts {
+		tr.Stats.sampleLayout(sample.Name, name, now)
+	}
+
+	return tr.Stats
+}
+
+func (tr *Tracker) statsFocus() {
+	c := tr.ActiveClient()
+	ws := tr.ClientWorkspace(c)
+	if ws == nil {
+		tr.Stats.sampleFocus("", 0, "", time.Now())
+		return
+	}
+
+	// Count focus changes of client
+	tr.Stats.client(c.Latest.Class).Focused += 1
+	tr.Stats.sampleFocus(c.Latest.Class, c.Window.Id, ws.Name, time.Now())
+}
+
+func (tr *Tracker) statsUntrack(c *store.Client) {
+
+	// Stop focus sample of untracked client
+	if tr.Stats.focus.Window == c.Window.Id {
+		tr.Stats.sampleFocus("", 0, "", time.Now())
+	}
+}
+
+func (tr *Tracker) statsRemove(ws *Workspace) {
+	now := time.Now()
+
+	// Stop running samples of removed workspace
+	if tr.Stats.focus.Workspace == ws.Name {
+		tr.Stats.sampleFocus("", 0, "", now)
+	}
+	if sample, ok := tr.Stats.layouts[ws.Name]; ok {
+		tr.Stats.sampleLayout(sample.Name, ws.Name, now)
+		delete(tr.Stats.layouts, ws.Name)
+	}
+}
+
+func (tr *Tracker) statsTile(ws *Workspace) {
+	tr.Stats.workspace(ws.Name).Tiles += 1
+	tr.Stats.sampleLayout(ws.ActiveLayout().GetName(), ws.Name, time.Now())
+}
+
+func (tr *Tracker) statsWrite(batch *common.CacheBatch) {
+	if !common.Config.CacheStats {
+		return
+	}
+
+	// Add statistics to batch
+	data, err := json.Marshal(tr.Statistics())
+	if err != nil {
+		log.Warn("Error parsing statistics: ", err)
+		return
+	}
+	batch.Add(StatsPath(), data)
+}
+
+func (s *Stats) sampleFocus(class string, window xproto.Window, workspace string, now time.Time) {
+
+	// Account focus time of previous sample
+	if previous := s.focus; len(previous.Name) > 0 {
+		elapsed := now.Sub(previous.Time).Milliseconds()
+		s.client(previous.Name).Focus += elapsed
+		s.workspace(previous.Workspace).Focus += elapsed
+	}
+
+	// Start next sample
+	s.focus = statsSample{Name: class, Window: window, Workspace: workspace, Time: now}
+}
+
+func (s *Stats) sampleLayout(layout string, workspace string, now time.Time) {
+
+	// Account residency time of previous layout
+	if previous, ok := s.layouts[workspace]; ok {
+		s.workspace(workspace).Layouts[previous.Name] += now.Sub(previous.Time).Milliseconds()
+	}
+	s.layouts[workspace] = statsSample{Name: layout, Workspace: workspace, Time: now}
+}
+
+func (s *Stats) workspace(name string) *WorkspaceStats {
+	if _, ok := s.Workspaces[name]; !ok {
+		s.Workspaces[name] = &WorkspaceStats{Layouts: map[string]int64{}}
+	}
+	return s.Workspaces[name]
+}
+
+func (s *Stats) client(class string) *ClientStats {
+	if _, ok := s.Clients[class]; !ok {
+		s.Clients[class] = &ClientStats{}
+	}
+	return s.Clients[class]
+}
@@ -1,6 +1,8 @@
 package desktop
 
 import (
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jezek/xgb/xproto"
@@ -8,6 +10,7 @@ import (
 	"github.com/jezek/xgbutil"
 	"github.com/jezek/xgbutil/xevent"
 	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
 
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/store"
@@ -16,36 +19,93 @@ import (
 )
 
 type Tracker struct {
-	Clients    map[xproto.Window]*store.Client // List of tracked clients
-	Workspaces map[store.Location]*Workspace   // List of workspaces per location
-	Channels   *Channels                       // Helper for channel communication
-	Handlers   *Handlers                       // Helper for event handlers
+	Clients    map[xproto.Window]*store.Client          // List of tracked clients
+	Workspaces map[store.Location]*Workspace            // List of workspaces per location
+	Channels   *Channels                                // Helper for channel communication
+	Handlers   *Handlers                                // Helper for event handlers
+	Stacked    []xproto.Window                          // Stacking order of previous update
+	Trackable  map[xproto.Window]bool                   // Cached trackable state per window
+	Settling   map[xproto.Window]bool                   // Pending settle delay per window
+	Floating   map[xproto.Window]bool                   // Manually floated windows
+	Selected   map[xproto.Window]bool                   // Selected clients for batch actions
+	Rejected   map[xproto.Window]store.Location         // Windows rejected by locked workspaces
+	Assigned   map[xproto.Window]bool                   // Windows with evaluated category rules
+	Pinned     map[xproto.Window]bool                   // Clients following the current desktop
+	Titled     map[xproto.Window]string                 // Matching title rule entry per window
+	Focused    map[store.Location][]Focus               // Focus history per workspace
+	Stats      *Stats                                   // Usage statistics of workspaces and clients
+	Audit      *Audit                                   // Recently executed actions
+	Displays   string                                   // Display setup of current workspaces
+	Arranged   map[string]map[xproto.Window]Arrangement // Client arrangements per display setup
+	Restoring  bool                                     // Restore client arrangements on next update
+}
+
+type Arrangement struct {
+	Location store.Location // Client workspace location
+	Master   bool           // Client is master
+}
+
+type Focus struct {
+	Window xproto.Window // Focused client window
+	Time   int64         // Focus timestamp
+}
 
+type Work struct {
+	Queue []func()   // Pending state mutations in submission order
+	Ready chan bool  // Signals pending state mutations
+	Lock  sync.Mutex // Lock for concurrent queue access
 }
+
 type Channels struct {
-	Event  chan string // Channel for events
-	Action chan string // Channel for actions
+	Event     chan string          // Channel for events
+	Action    chan string          // Channel for actions
+	Work      *Work                // Queue for state mutations
+	Consumers map[uint]chan string // Channels of registered event consumers
+	Emitted   []string             // Emitted event types replayed to late consumers
+	Consumer  uint                 // Id of last registered event consumer
+	Dropped   uint                 // Number of events dropped on overflow
 }
 
 type Handlers struct {
 	Timer        *time.Timer // Timer to handle delayed structure events
+	Writer       *time.Timer // Timer to handle delayed cache writes
+	Deferred     *Deferred   // Stores deferred work while idle
+	Pending      bool        // Indicates scheduled cache writes
+	Stale        time.Time   // Time of the first scheduled cache write
+	KeyboardMove *Handler    // Stores client for keyboard move/resize
 	ResizeClient *Handler    // Stores client for proportion change
 	MoveClient   *Handler    // Stores client for tiling after move
 	SwapClient   *Handler    // Stores clients for window swap
 	SwapScreen   *Handler    // Stores client for screen swap
+	Stepped      Step        // Stores latest move/resize step without pressed buttons
+}
+
+type Step struct {
+	Source *store.Client // Moved/resized client
+	Time   time.Time     // Time of move/resize step
+}
+
+func (h *Handlers) Dragging() bool {
+	return h.KeyboardMove.Dragging || h.ResizeClient.Dragging || h.MoveClient.Dragging
 }
 
 func (h *Handlers) Active() bool {
-	return h.ResizeClient.Active() || h.MoveClient.Active() || h.SwapClient.Active() || h.SwapScreen.Active()
+	return h.KeyboardMove.Active() || h.ResizeClient.Active() || h.MoveClient.Active() || h.SwapClient.Active() || h.SwapScreen.Active()
 }
 
 func (h *Handlers) Reset() {
+	h.KeyboardMove.Reset()
 	h.ResizeClient.Reset()
 	h.MoveClient.Reset()
 	h.SwapClient.Reset()
 	h.SwapScreen.Reset()
 }
 
+type Deferred struct {
+	Update bool // Indicates deferred client updates
+	Write  bool // Indicates deferred cache writes
+}
+
 type Handler struct {
 	Dragging bool        // Indicates pointer dragging event
 	Source   interface{} // Stores moved/resized client
@@ -60,55 +120,325 @@ func (h *Handler) Reset() {
 	*h = Handler{}
 }
 
+var (
+	titleTimers = map[xproto.Window]*time.Timer{} // Timers to debounce title changes per window
+	titleDelay  = time.Duration(250)              // Delay [ms] until changed titles are evaluated
+	stepDelay   = time.Duration(1000)             // Delay [ms] between consecutive keyboard move/resize steps
+	focusDelay  = time.Duration(150)              // Delay [ms] until focus is restored after desktop switches
+	focusLimit  = 32                              // Maximum number of focus history entries per workspace
+)
+
 func CreateTracker() *Tracker {
 	tr := Tracker{
 		Clients:    make(map[xproto.Window]*store.Client),
 		Workspaces: CreateWorkspaces(),
+		Trackable:  make(map[xproto.Window]bool),
+		Settling:   make(map[xproto.Window]bool),
+		Floating:   make(map[xproto.Window]bool),
+		Selected:   make(map[xproto.Window]bool),
+		Rejected:   make(map[xproto.Window]store.Location),
+		Assigned:   make(map[xproto.Window]bool),
+		Pinned:     make(map[xproto.Window]bool),
+		Titled:     make(map[xproto.Window]string),
+		Focused:    make(map[store.Location][]Focus),
+		Stats:      CreateStats(),
+		Audit:      CreateAudit(256),
+		Displays:   store.Workplace.Displays.Fingerprint,
+		Arranged:   make(map[string]map[xproto.Window]Arrangement),
 		Channels: &Channels{
-			Event:  make(chan string),
-			Action: make(chan string),
+			Event:     make(chan string, buffer()),
+			Action:    make(chan string, buffer()),
+			Work:      &Work{Ready: make(chan bool, 1)},
+			Consumers: make(map[uint]chan string),
 		},
 		Handlers: &Handlers{
+			KeyboardMove: &Handler{},
 			ResizeClient: &Handler{},
 			MoveClient:   &Handler{},
 			SwapClient:   &Handler{},
 			SwapScreen:   &Handler{},
+			Deferred:     &Deferred{},
 		},
 	}
 
+	// Push workspace names
+	tr.UpdateNames()
+
+	// Load reserved regions of current displays
+	store.ReservationsLoad()
+
+	// Skip category rules for windows existing on startup
+	for _, w := range store.Windows.Stacked {
+		tr.Assigned[w.Id] = true
+	}
+
+	// Start state worker
+	go tr.work()
+
+	// Process queued root events on state worker
+	store.OnEventsDispatch(tr.Do)
+
 	// Attach to root events
-	store.OnStateUpdate(tr.onStateUpdate)
-	store.OnPointerUpdate(tr.onPointerUpdate)
+	store.OnStateUpdate(func(state string, desktop uint, screen uint) {
+		tr.Do(func() { tr.onStateUpdate(state, desktop, screen) })
+	})
+	store.OnPointerUpdate(func(pointer store.XPointer, desktop uint, screen uint) {
+		tr.Do(func() { tr.onPointerUpdate(pointer, desktop, screen) })
+	})
+	store.OnMoveResizeUpdate(func(w xproto.Window, direction uint32) {
+		tr.Do(func() { tr.onMoveResizeUpdate(w, direction) })
+	})
+	store.OnIdleUpdate(func(idle store.XIdle) {
+		tr.Do(func() { tr.onIdleUpdate(idle) })
+	})
+	store.OnPropertyUpdate(func(w xproto.Window, atom string) {
+		tr.Do(func() { tr.onPropertyUpdate(w, atom) })
+	})
+	store.OnErrorUpdate(func(w xproto.Window) {
+		tr.Do(func() { tr.onErrorUpdate(w) })
+	})
 
 	return &tr
 }
 
+func (tr *Tracker) Do(fun func()) {
+	work := tr.Channels.Work
+
+	// Append to unbounded queue to keep submission order
+	work.Lock.Lock()
+	work.Queue = append(work.Queue, fun)
+	work.Lock.Unlock()
+
+	// Wake up state worker
+	select {
+	case work.Ready <- true:
+	default:
+	}
+}
+
+func (tr *Tracker) Pending() int {
+	work := tr.Channels.Work
+
+	work.Lock.Lock()
+	defer work.Lock.Unlock()
+
+	return len(work.Queue)
+}
+
+func (tr *Tracker) Exec(fun func()) {
+	done := make(chan bool)
+
+	// Wait until state worker executed function
+	tr.Do(func() {
+		defer close(done)
+		fun()
+	})
+	<-done
+}
+
+func (tr *Tracker) Alive(timeout time.Duration) bool {
+	done := make(chan bool)
+
+	// Check if state worker responds in time
+	tr.Do(func() { close(done) })
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (tr *Tracker) Emit(event string) {
+	if !common.IsInList(event, tr.Channels.Emitted) {
+		tr.Channels.Emitted = append(tr.Channels.Emitted, event)
+	}
+	tr.Send(tr.Channels.Event, event)
+
+	// Fan out to registered consumers
+	for _, ch := range tr.Channels.Consumers {
+		tr.Send(ch, event)
+	}
+}
+
+func (tr *Tracker) Send(ch chan string, value string) {
+	for {
+		select {
+		case ch <- value:
+			return
+		default:
+		}
+
+		// Drop oldest value on overflow
+		select {
+		case dropped := <-ch:
+			tr.Channels.Dropped += 1
+			log.Trace("Drop channel value on overflow ", dropped, " [", tr.Channels.Dropped, "]")
+		default:
+		}
+	}
+}
+
+func (tr *Tracker) Register() (uint, chan string) {
+	ch := make(chan string, buffer())
+
+	// Register event consumer
+	var id uint
+	tr.Exec(func() {
+		tr.Channels.Consumer += 1
+		id = tr.Channels.Consumer
+		tr.Channels.Consumers[id] = ch
+		tr.Replay(ch)
+	})
+
+	return id, ch
+}
+
+func (tr *Tracker) Replay(ch chan string) {
+
+	// Send emitted event types to catch up on current state
+	for _, event := range tr.Channels.Emitted {
+		tr.Send(ch, event)
+	}
+}
+
+func (tr *Tracker) Unregister(id uint) {
+	tr.Exec(func() {
+		delete(tr.Channels.Consumers, id)
+	})
+}
+
 func (tr *Tracker) Update() {
 	ws := tr.ActiveWorkspace()
 	if ws.TilingDisabled() {
 		return
 	}
-	log.Debug("Update trackable clients [", len(tr.Clients), "/", len(store.Windows.Stacked), "]")
 
-	// Map trackable windows
-	trackable := make(map[xproto.Window]bool)
-	for _, w := range store.Windows.Stacked {
-		trackable[w.Id] = tr.isTrackable(w.Id)
+	// Obtain stacking changes
+	stacked := make([]xproto.Window, len(store.Windows.Stacked))
+	for i, w := range store.Windows.Stacked {
+		stacked[i] = w.Id
+	}
+	added, removed, restacked := stackingDiff(tr.Stacked, stacked)
+	mapped := map[xproto.Window]bool{}
+	if tr.Stacked != nil {
+		for _, w := range added {
+			mapped[w] = true
+		}
+	}
+	tr.Stacked = stacked
+
+	log.Debug("Update trackable clients [", len(tr.Clients), "/", len(stacked), ", +", len(added), " -", len(removed), " ~", len(restacked), "]")
+
+	// Forget removed windows
+	for _, w := range removed {
+		delete(tr.Trackable, w)
+		delete(tr.Settling, w)
+		delete(tr.Floating, w)
+		delete(tr.Rejected, w)
+		delete(tr.Assigned, w)
+		delete(tr.Pinned, w)
+		delete(tr.Titled, w)
+		store.Unguard(w)
+		store.ForgetClient(w)
+		if !tr.isTracked(w) {
+			xevent.Detach(store.X, w)
+			store.UnwatchProperties(w)
+		}
+	}
+
+	// Obtain new and changed windows
+	windows := []xproto.Window{}
+	for _, w := range stacked {
+		if _, ok := tr.Trackable[w]; !ok {
+			windows = append(windows, w)
+		}
+	}
+	infos := store.GetInfoBatch(windows)
+
+	// Evaluate new and changed windows
+	for _, w := range windows {
+		tr.Trackable[w] = !tr.Floating[w] && !store.IsSpecial(infos[w]) && !store.IsIgnored(infos[w]) && !((mapped[w] || tr.Settling[w]) && tr.settle(w, infos[w]))
+		tr.watchWindow(w)
 	}
 
 	// Remove untrackable windows
 	for w := range tr.Clients {
-		if !trackable[w] {
+		if !tr.Trackable[w] {
 			tr.untrackWindow(w)
 		}
 	}
 
 	// Add trackable windows
-	for _, w := range store.Windows.Stacked {
-		if trackable[w.Id] {
-			tr.trackWindow(w.Id)
+	for _, w := range stacked {
+		if tr.Trackable[w] && !tr.isTracked(w) {
+			tr.trackWindow(w)
 		}
 	}
+
+	// Restore arrangement of previous display setup
+	if tr.Restoring {
+		tr.Restoring = false
+		tr.Rearrange()
+	}
+}
+
+func (tr *Tracker) Arrange() {
+	if len(tr.Displays) == 0 || tr.Displays == store.Workplace.Displays.Fingerprint {
+		return
+	}
+
+	// Remember client arrangement of previous display setup
+	arranged := make(map[xproto.Window]Arrangement)
+	for w, c := range tr.Clients {
+		ws := tr.ClientWorkspace(c)
+		if ws == nil {
+			continue
+		}
+		arranged[w] = Arrangement{Location: ws.Location, Master: ws.ActiveLayout().GetManager().IsMaster(c)}
+	}
+	tr.Arranged[tr.Displays] = arranged
+
+	log.Info("Remember arrangement of ", len(arranged), " windows [", tr.Displays, "]")
+
+	// Restore arrangement of new display setup on next update
+	tr.Displays = store.Workplace.Displays.Fingerprint
+	tr.Restoring = true
+}
+
+func (tr *Tracker) Rearrange() {
+	arranged, ok := tr.Arranged[tr.Displays]
+	if !ok {
+		return
+	}
+	delete(tr.Arranged, tr.Displays)
+
+	// Move clients back to their previous screen
+	tiles := make(map[*Workspace]bool)
+	for w, a := range arranged {
+		c, ok := tr.Clients[w]
+		if !ok || c.Latest.Location.Desktop != a.Location.Desktop || c.Latest.Location.Screen == a.Location.Screen {
+			continue
+		}
+		ws, target := tr.ClientWorkspace(c), tr.WorkspaceAt(a.Location.Desktop, a.Location.Screen)
+		if ws == nil || target == nil || target.TilingDisabled() {
+			continue
+		}
+		ws.RemoveClient(c)
+		c.Latest.Location.Screen = a.Location.Screen
+		target.AddClient(c)
+		if a.Master {
+			target.ActiveLayout().GetManager().MakeMaster(c)
+		}
+		tiles[ws], tiles[target] = true, true
+	}
+
+	// Tile affected workspaces
+	for ws := range tiles {
+		tr.Tile(ws)
+	}
+
+	log.Info("Restore arrangement of ", len(arranged), " windows [", tr.Displays, "]")
 }
 
 func (tr *Tracker) Reset() {
@@ -118,28 +448,217 @@ func (tr *Tracker) Reset() {
 	for w := range tr.Clients {
 		tr.untrackWindow(w)
 	}
+	tr.Stacked = nil
+	tr.Trackable = make(map[xproto.Window]bool)
 
 	// Reset workspaces
+	for _, ws := range tr.Workspaces {
+		tr.statsRemove(ws)
+	}
 	tr.Workspaces = CreateWorkspaces()
+	tr.UpdateNames()
 
 	// Communicate workplace change
-	tr.Channels.Event <- "workplace_change"
+	tr.Emit("workplace_change")
+}
+
+func (tr *Tracker) Unfloat() bool {
+	if len(tr.Floating) == 0 {
+		return false
+	}
+	log.Debug("Reset floating windows [", len(tr.Floating), "]")
+
+	// Re-evaluate floating windows
+	for w := range tr.Floating {
+		delete(tr.Trackable, w)
+	}
+	tr.Floating = make(map[xproto.Window]bool)
+
+	return true
+}
+
+func (tr *Tracker) FloatClient(c *store.Client) bool {
+	if c == nil || !tr.isTracked(c.Window.Id) {
+		return false
+	}
+
+	// Untrack client at current position
+	tr.Floating[c.Window.Id] = true
+	tr.Trackable[c.Window.Id] = false
+	c.Update()
+
+	return tr.untrackWindow(c.Window.Id)
+}
+
+func (tr *Tracker) SelectClient(c *store.Client) bool {
+	if c == nil || !tr.isTracked(c.Window.Id) {
+		return false
+	}
+
+	// Toggle client selection
+	if tr.Selected[c.Window.Id] {
+		log.Info("Unselect window [", c.Latest.Class, "]")
+		delete(tr.Selected, c.Window.Id)
+	} else {
+		log.Info("Select window [", c.Latest.Class, "]")
+		tr.Selected[c.Window.Id] = true
+	}
+
+	return true
+}
+
+func (tr *Tracker) SelectedClients() []*store.Client {
+	clients := []*store.Client{}
+
+	// Obtain selected clients in stacking order
+	for _, w := range store.Windows.Stacked {
+		if c, ok := tr.Clients[w.Id]; ok && tr.Selected[w.Id] {
+			clients = append(clients, c)
+		}
+	}
+
+	return clients
+}
+
+func (tr *Tracker) ClearSelection() bool {
+	if len(tr.Selected) == 0 {
+		return false
+	}
+	log.Info("Clear window selection [", len(tr.Selected), "]")
+
+	// Reset selected clients
+	tr.Selected = make(map[xproto.Window]bool)
+
+	return true
+}
+
+func (tr *Tracker) MoveToScreen(c *store.Client, screen uint) bool {
+	ws := tr.ClientWorkspace(c)
+	target := tr.WorkspaceAt(c.Latest.Location.Desktop, screen)
+	if ws == nil || target == nil || ws == target || target.TilingDisabled() {
+		return false
+	}
+	if common.Args.DryRun {
+		log.Info("Dry-run MoveToScreen ", screen, " [", c.Latest.Class, "]")
+		return false
+	}
+	log.Info("Move window to screen ", screen, " [", c.Latest.Class, "]")
+
+	// Move client into target workspace
+	master := ws.ActiveLayout().GetManager().IsMaster(c)
+	ws.RemoveClient(c)
+	c.Latest.Location.Screen = screen
+	target.AddClient(c)
+	if master {
+		target.ActiveLayout().MakeMaster(c)
+	}
+
+	// Tile both workspaces
+	if ws.TilingEnabled() {
+		tr.Tile(ws)
+	}
+	tr.Tile(target)
+
+	return true
+}
+
+func (tr *Tracker) Resize() {
+	log.Debug("Resize workspaces [", len(tr.Workspaces), "/", store.Workplace.DesktopCount*store.Workplace.ScreenCount, "]")
+
+	// Remove obsolete workspaces
+	for location, ws := range tr.Workspaces {
+		if location.Desktop < store.Workplace.DesktopCount && location.Screen < store.Workplace.ScreenCount {
+			continue
+		}
+
+		// Untrack clients of obsolete workspace
+		for w, c := range tr.Clients {
+			if tr.ClientWorkspace(c) == ws {
+				tr.untrackWindow(w)
+			}
+		}
+		tr.statsRemove(ws)
+		delete(tr.Workspaces, location)
+	}
+
+	// Add missing workspaces
+	for desktop := uint(0); desktop < store.Workplace.DesktopCount; desktop++ {
+		for screen := uint(0); screen < store.Workplace.ScreenCount; screen++ {
+			location := store.Location{Desktop: desktop, Screen: screen}
+			if _, ok := tr.Workspaces[location]; ok {
+				continue
+			}
+			tr.Workspaces[location] = CreateWorkspace(location)
+		}
+	}
+	tr.UpdateNames()
+
+	// Communicate workplace change
+	tr.Emit("workplace_change")
 }
 
 func (tr *Tracker) Write() {
+	batch := common.CreateCacheBatch()
+	tr.Handlers.Pending = false
 
 	// Write client cache
 	for _, c := range tr.Clients {
-		c.Write()
+		c.Write(batch)
 	}
 
 	// Write workspace cache
 	for _, ws := range tr.Workspaces {
-		ws.Write()
+		ws.Write(batch)
+	}
+
+	// Write usage statistics
+	tr.statsWrite(batch)
+
+	// Commit cache files at once
+	if err := batch.Commit(); err != nil {
+		log.Warn("Error writing cache: ", err)
+	} else {
+		TruncateJournal()
 	}
 
 	// Communicate windows change
-	tr.Channels.Event <- "windows_change"
+	tr.Emit("windows_change")
+}
+
+func (tr *Tracker) WriteDelayed() {
+	if store.Idle.Active {
+		tr.Handlers.Deferred.Write = true
+		return
+	}
+	delay := common.Power().Profile.WriteDelay
+	if common.Config.CacheWriteDelay != 0 {
+		delay = common.Config.CacheWriteDelay
+	}
+	if delay <= 0 {
+		tr.Write()
+		return
+	}
+
+	// Reset timer
+	if tr.Handlers.Writer != nil {
+		tr.Handlers.Writer.Stop()
+	}
+
+	// Flush cache writes postponed for too long
+	stale := time.Duration(common.Config.CacheWriteMax) * time.Second
+	if tr.Handlers.Pending && stale > 0 && time.Since(tr.Handlers.Stale) >= stale {
+		tr.Write()
+		return
+	}
+
+	// Delay cache writes
+	if !tr.Handlers.Pending {
+		tr.Handlers.Stale = time.Now()
+	}
+	tr.Handlers.Pending = true
+	tr.Handlers.Writer = time.AfterFunc(time.Duration(delay)*time.Millisecond, func() {
+		tr.Do(tr.Write)
+	})
 }
 
 func (tr *Tracker) Tile(ws *Workspace) {
@@ -149,12 +668,16 @@ func (tr *Tracker) Tile(ws *Workspace) {
 
 	// Tile workspace
 	ws.Tile()
+	tr.statsTile(ws)
+
+	// Journal workspace state
+	ws.Journal()
 
 	// Communicate clients change
-	tr.Channels.Event <- "clients_change"
+	tr.Emit("clients_change")
 
 	// Communicate workspaces change
-	tr.Channels.Event <- "workspaces_change"
+	tr.Emit("workspaces_change")
 }
 
 func (tr *Tracker) Restore(ws *Workspace, flag uint8) {
@@ -162,11 +685,14 @@ func (tr *Tracker) Restore(ws *Workspace, flag uint8) {
 	// Restore workspace
 	ws.Restore(flag)
 
+	// Journal workspace state
+	ws.Journal()
+
 	// Communicate clients change
-	tr.Channels.Event <- "clients_change"
+	tr.Emit("clients_change")
 
 	// Communicate workspaces change
-	tr.Channels.Event <- "workspaces_change"
+	tr.Emit("workspaces_change")
 }
 
 func (tr *Tracker) ActiveWorkspace() *Workspace {
@@ -195,6 +721,107 @@ func (tr *Tracker) WorkspaceAt(desktop uint, screen uint) *Workspace {
 	return ws
 }
 
+func (tr *Tracker) WorkspaceNamed(name string) *Workspace {
+
+	// Match workspace names and aliases
+	for _, ws := range tr.Workspaces {
+		if ws.HasName(name) {
+			return ws
+		}
+	}
+
+	// Match desktop names on current screen
+	for desktop, desktopName := range store.DesktopNamesGet(store.X) {
+		if strings.EqualFold(strings.TrimSpace(desktopName), strings.TrimSpace(name)) {
+			return tr.WorkspaceAt(uint(desktop), store.Workplace.CurrentScreen)
+		}
+	}
+
+	return nil
+}
+
+func (tr *Tracker) RenameWorkspace(ws *Workspace, name string) {
+	if ws == nil {
+		return
+	}
+
+	// Rename workspace
+	ws.Rename(name)
+	batch := common.CreateCacheBatch()
+	ws.Write(batch)
+	batch.Commit()
+
+	// Push workspace names
+	tr.UpdateNames()
+
+	// Communicate workspaces change
+	tr.Emit("workspaces_change")
+}
+
+func (tr *Tracker) UpdateNames() {
+	aliases := make([][]string, store.Workplace.DesktopCount)
+
+	// Collect workspace aliases ordered by screen
+	for screen := uint(0); screen < store.Workplace.ScreenCount; screen++ {
+		for desktop := uint(0); desktop < store.Workplace.DesktopCount; desktop++ {
+			ws := tr.Workspaces[store.Location{Desktop: desktop, Screen: screen}]
+			if ws == nil || len(ws.Alias) == 0 {
+				continue
+			}
+			aliases[desktop] = append(aliases[desktop], ws.Alias)
+		}
+	}
+
+	// Update desktop names
+	for desktop, names := range aliases {
+		store.DesktopNameSet(store.X, uint(desktop), strings.Join(names, " | "))
+	}
+}
+
+func (tr *Tracker) EmptyDesktop() (uint, bool) {
+	occupied := make(map[uint]bool)
+
+	// Map desktops with tracked clients
+	for _, c := range tr.Clients {
+		if store.IsSticky(c.Latest) {
+			continue
+		}
+		occupied[c.Latest.Location.Desktop] = true
+	}
+
+	// Find first desktop without clients
+	for desktop := uint(0); desktop < store.Workplace.DesktopCount; desktop++ {
+		if !occupied[desktop] {
+			return desktop, true
+		}
+	}
+
+	return store.Workplace.DesktopCount, false
+}
+
+func (tr *Tracker) OccupiedDesktop(offset int) (uint, bool) {
+	occupied := make(map[uint]bool)
+
+	// Map desktops with tracked clients
+	for _, c := range tr.Clients {
+		if store.IsSticky(c.Latest) {
+			continue
+		}
+		occupied[c.Latest.Location.Desktop] = true
+	}
+
+	// Find next desktop with clients in offset direction
+	count := int(store.Workplace.DesktopCount)
+	for i := 1; i < count; i++ {
+		desktop := uint(((int(store.Workplace.CurrentDesktop)+i*offset)%count + count) % count)
+		if occupied[desktop] {
+			return desktop, true
+		}
+	}
+
+	return store.Workplace.CurrentDesktop, false
+}
+
 func (tr *Tracker) ClientAt(ws *Workspace, p common.Point) *store.Client {
 	if ws == nil {
 		return nil
@@ -213,6 +840,24 @@ func (tr *Tracker) ClientAt(ws *Workspace, p common.Point) *store.Client {
 	return nil
 }
 
+func (tr *Tracker) DropTarget() (*store.Client, string) {
+	if !tr.Handlers.MoveClient.Dragging || !tr.Handlers.SwapClient.Active() {
+		return nil, ""
+	}
+	target := tr.Handlers.SwapClient.Target.(*store.Client)
+
+	// Obtain drop position on target client
+	p, ok := tr.Handlers.MoveClient.Target.(common.Point)
+	if !ok || common.Config.WindowDrop != "insert" {
+		return target, "swap"
+	}
+	if secondHalf(p, target.Latest.Dimensions.Geometry) {
+		return target, "after"
+	}
+
+	return target, "before"
+}
+
 func (tr *Tracker) ActiveClient() *store.Client {
 	c, exists := tr.Clients[store.Windows.Active.Id]
 
@@ -245,21 +890,110 @@ func (tr *Tracker) trackWindow(w xproto.Window) bool {
 		return false
 	}
 
-	// Client and workspace
+	// Skip windows rejected by still locked workspaces
+	if location, ok := tr.Rejected[w]; ok {
+		if ws, ok := tr.Workspaces[location]; ok && ws.Locked {
+			return false
+		}
+		delete(tr.Rejected, w)
+	}
+
+	// Client and placement of newly mapped windows
 	c := store.CreateClient(w)
+	var placement *store.Placement
+	if !tr.Assigned[w] {
+
+		// Startup placement
+		placement = store.PlacementGet(c.Latest)
+
+		// Category placement
+		if placement == nil {
+			placement = store.CategoryGet(w, c.Latest)
+			if placement != nil {
+				log.Info("Send window to category desktop ", placement.Desktop, " [", c.Latest.Class, "]")
+			}
+		}
+	}
+	tr.Assigned[w] = true
+	if placement != nil && placement.Desktop != c.Latest.Location.Desktop && c.MoveToDesktop(uint32(placement.Desktop)) {
+		c.Latest.Location.Desktop = placement.Desktop
+	}
+
+	// Client workspace
 	ws := tr.ClientWorkspace(c)
 	if ws == nil {
 		return false
 	}
+	if ws.Locked {
+		tr.Rejected[w] = ws.Location
+		return false
+	}
 
 	// Add new client
 	tr.Clients[c.Window.Id] = c
 	ws.AddClient(c)
+	if placement != nil {
+		tr.placeClient(ws, c, placement.Slot)
+	}
 
 	// Attach handlers
 	tr.attachHandlers(c)
 	tr.Tile(ws)
 
+	// Follow placed client
+	if placement != nil && placement.Follow && placement.Desktop != store.Workplace.CurrentDesktop {
+		store.CurrentDesktopSet(store.X, placement.Desktop)
+	}
+
+	return true
+}
+
+func (tr *Tracker) placeClient(ws *Workspace, c *store.Client, slot string) {
+	for _, l := range ws.Layouts {
+		mg := l.GetManager()
+
+		// Move client into layout area
+		switch slot {
+		case "master":
+			if !mg.IsMaster(c) {
+				mg.MakeMaster(c)
+			}
+		case "slave":
+			if mg.IsMaster(c) && len(mg.Slaves.Stacked) > 0 {
+				mg.SwapClient(c, mg.Slaves.Stacked[0])
+			}
+		}
+	}
+}
+
+func (tr *Tracker) settle(w xproto.Window, info *store.Info) bool {
+	if tr.isTracked(w) {
+		return false
+	}
+
+	// Check pending or elapsed settle delay
+	if pending, ok := tr.Settling[w]; ok {
+		return pending
+	}
+	delay := store.SettleGet(info)
+	if delay <= 0 {
+		return false
+	}
+	log.Info("Delay window tracking for ", delay, " [", info.Class, "]")
+
+	// Re-evaluate window after delay
+	tr.Settling[w] = true
+	time.AfterFunc(delay, func() {
+		tr.Do(func() {
+			if _, ok := tr.Settling[w]; !ok {
+				return
+			}
+			tr.Settling[w] = false
+			delete(tr.Trackable, w)
+			tr.Update()
+		})
+	})
+
 	return true
 }
 
@@ -277,13 +1011,19 @@ func (tr *Tracker) untrackWindow(w xproto.Window) bool {
 
 	// Detach events
 	xevent.Detach(store.X, w)
+	store.UnwatchProperties(w)
+	if _, ok := tr.Trackable[w]; ok {
+		tr.watchWindow(w)
+	}
 
 	// Restore client
 	c.Restore(store.Latest)
+	tr.statsUntrack(c)
 
 	// Remove client
 	ws.RemoveClient(c)
 	delete(tr.Clients, w)
+	delete(tr.Selected, w)
 
 	// Tile workspace
 	tr.Tile(ws)
@@ -291,6 +1031,36 @@ func (tr *Tracker) untrackWindow(w xproto.Window) bool {
 	return true
 }
 
+func (tr *Tracker) handleGameMode() {
+	if !common.Config.GameMode {
+		return
+	}
+
+	// Check if active window is a game
+	info := store.GetInfo(store.Windows.Active.Id)
+	target := tr.WorkspaceAt(info.Location.Desktop, info.Location.Screen)
+	game := target != nil && store.IsGame(info) && target.ActiveLayout().GetName() != "fullscreen"
+
+	// Resume suspended workspaces
+	for _, ws := range tr.Workspaces {
+		if !ws.Suspended || (game && ws == target) {
+			continue
+		}
+		log.Info("Resume tiling after game mode [", ws.Name, "]")
+
+		ws.Suspended = false
+		tr.Tile(ws)
+	}
+
+	// Suspend game workspace
+	if game && target.TilingEnabled() {
+		log.Info("Suspend tiling for game mode [", info.Class, ", ", target.Name, "]")
+
+		target.Suspended = true
+		tr.Emit("workspaces_change")
+	}
+}
+
 func (tr *Tracker) handleMaximizedClient(c *store.Client) {
 	if !tr.isTracked(c.Window.Id) {
 		return
@@ -312,7 +1082,7 @@ func (tr *Tracker) handleMaximizedClient(c *store.Client) {
 
 		// Activate maximized layout
 		if !c.IsNew() && ws.ActiveLayout().GetName() != "maximized" {
-			tr.Channels.Action <- "layout_maximized"
+			tr.Send(tr.Channels.Action, "layout_maximized")
 			store.ActiveWindowSet(store.X, c.Window)
 		}
 	}
@@ -338,7 +1108,7 @@ func (tr *Tracker) handleMinimizedClient(c *store.Client) {
 
 func (tr *Tracker) handleResizeClient(c *store.Client) {
 	ws := tr.ClientWorkspace(c)
-	if ws.TilingDisabled() || !tr.isTracked(c.Window.Id) || store.IsMaximized(store.GetInfo(c.Window.Id)) {
+	if ws.TilingDisabled() || !tr.isTracked(c.Window.Id) || c.Animating() || store.IsMaximized(store.GetInfo(c.Window.Id)) {
 		return
 	}
 
@@ -361,12 +1131,16 @@ func (tr *Tracker) handleResizeClient(c *store.Client) {
 		pt := store.PointerUpdate(store.X)
 
 		// Set client resize event
+		tr.detectKeyboardMove(c, pt)
 		if !c.IsNew() && !tr.Handlers.ResizeClient.Active() {
-			tr.Handlers.ResizeClient = &Handler{Dragging: pt.Dragging(500), Source: c}
+			tr.Handlers.ResizeClient = &Handler{Dragging: pt.Dragging(500) || tr.keyboardMoved(c), Source: c}
+		}
+		if tr.keyboardMoved(c) && tr.Handlers.ResizeClient.Active() {
+			tr.Handlers.ResizeClient.Dragging = true
 		}
 		log.Debug("Client resize handler fired [", c.Latest.Class, "]")
 
-		if tr.Handlers.ResizeClient.Dragging {
+		if tr.Handlers.ResizeClient.Dragging && !ws.Locked {
 
 			// Set client resize lock
 			if tr.Handlers.ResizeClient.Active() {
@@ -391,7 +1165,7 @@ func (tr *Tracker) handleResizeClient(c *store.Client) {
 
 func (tr *Tracker) handleMoveClient(c *store.Client) {
 	ws := tr.ClientWorkspace(c)
-	if !tr.isTracked(c.Window.Id) || store.IsMaximized(store.GetInfo(c.Window.Id)) {
+	if !tr.isTracked(c.Window.Id) || c.Animating() || store.IsMaximized(store.GetInfo(c.Window.Id)) {
 		return
 	}
 
@@ -414,16 +1188,23 @@ func (tr *Tracker) handleMoveClient(c *store.Client) {
 		pt := store.PointerUpdate(store.X)
 
 		// Set client move event
+		tr.detectKeyboardMove(c, pt)
 		if !c.IsNew() && !tr.Handlers.MoveClient.Active() {
-			tr.Handlers.MoveClient = &Handler{Dragging: pt.Dragging(500), Source: c}
+			tr.Handlers.MoveClient = &Handler{Dragging: pt.Dragging(500) || tr.keyboardMoved(c), Source: c}
+		}
+		if tr.keyboardMoved(c) && tr.Handlers.MoveClient.Active() {
+			tr.Handlers.MoveClient.Dragging = true
 		}
 		log.Debug("Client move handler fired [", c.Latest.Class, "]")
 
 		// Obtain targets based on dragging indicator
 		targetPoint := *common.CreatePoint(cx, cy)
-		if tr.Handlers.MoveClient.Dragging {
+		if tr.keyboardMoved(c) {
+			targetPoint = common.CreateGeometry(cGeom).Center()
+		} else if tr.Handlers.MoveClient.Dragging {
 			targetPoint = pt.Position
 		}
+		tr.Handlers.MoveClient.Target = targetPoint
 		targetDesktop := store.Workplace.CurrentDesktop
 		targetScreen := store.ScreenGet(targetPoint)
 
@@ -443,6 +1224,27 @@ func (tr *Tracker) handleMoveClient(c *store.Client) {
 	}
 }
 
+func (tr *Tracker) handleFloatClient(h *Handler) {
+	c, target := h.Source.(*store.Client), h.Target
+	if !common.Config.WindowFloatDrop || !h.Dragging || !tr.isTracked(c.Window.Id) {
+		return
+	}
+
+	// Ignore windows dropped onto their own tile
+	p, ok := target.(common.Point)
+	if !ok || common.IsInsideRect(p, c.Latest.Dimensions.Geometry) {
+		return
+	}
+	ws := tr.ClientWorkspace(c)
+	if ws == nil || ws.TilingDisabled() {
+		return
+	}
+	log.Info("Float window at dropped position [", c.Latest.Class, "]")
+
+	// Untrack client at current position
+	tr.FloatClient(c)
+}
+
 func (tr *Tracker) handleSwapClient(h *Handler) {
 	c, target := h.Source.(*store.Client), h.Target.(*store.Client)
 	ws := tr.ClientWorkspace(c)
@@ -451,9 +1253,15 @@ func (tr *Tracker) handleSwapClient(h *Handler) {
 	}
 	log.Debug("Client swap handler fired [", c.Latest.Class, "-", target.Latest.Class, "]")
 
-	// Swap clients on same desktop and screen
+	// Swap or insert clients on same desktop and screen
 	mg := ws.ActiveLayout().GetManager()
-	mg.SwapClient(c, target)
+	if ws.Locked {
+		log.Info("Reject window swap on locked layout [", ws.Name, "]")
+	} else if _, side := tr.DropTarget(); side == "before" || side == "after" {
+		mg.InsertClient(c, target, side == "after")
+	} else {
+		mg.SwapClient(c, target)
+	}
 
 	// Reset client swapping handler
 	h.Reset()
@@ -471,6 +1279,9 @@ func (tr *Tracker) handleWorkspaceChange(h *Handler) {
 
 	// Remove client from current workspace
 	ws := tr.ClientWorkspace(c)
+	if ws == nil {
+		return
+	}
 	mg := ws.ActiveLayout().GetManager()
 	master := mg.IsMaster(c)
 	ws.RemoveClient(c)
@@ -512,14 +1323,26 @@ func (tr *Tracker) onStateUpdate(state string, desktop uint, screen uint) {
 	workplaceChanged := store.Workplace.DesktopCount*store.Workplace.ScreenCount != uint(len(tr.Workspaces))
 	workspaceChanged := common.IsInList(state, []string{"_NET_CURRENT_DESKTOP"})
 
-	viewportChanged := common.IsInList(state, []string{"_NET_NUMBER_OF_DESKTOPS", "_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA"})
+	viewportChanged := common.IsInList(state, []string{"_NET_NUMBER_OF_DESKTOPS", "_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA", "_NET_WM_STRUT", "_NET_WM_STRUT_PARTIAL"})
 	clientsChanged := common.IsInList(state, []string{"_NET_CLIENT_LIST_STACKING"})
 	focusChanged := common.IsInList(state, []string{"_NET_ACTIVE_WINDOW"})
 
+	// Load reserved regions of changed displays
+	if workplaceChanged || viewportChanged {
+		store.ReservationsLoad()
+	}
+
 	if workplaceChanged {
+		if tr.screens() == store.Workplace.ScreenCount {
 
-		// Reset clients and workspaces
-		tr.Reset()
+			// Resize workspaces on desktop changes
+			tr.Resize()
+		} else {
+
+			// Reset clients and workspaces
+			tr.Arrange()
+			tr.Reset()
+		}
 	}
 
 	if workspaceChanged {
@@ -530,9 +1353,33 @@ func (tr *Tracker) onStateUpdate(state string, desktop uint, screen uint) {
 				c.MoveToDesktop(^uint32(0))
 			}
 		}
+
+		// Update pinned windows
+		for w := range tr.Pinned {
+			if c, ok := tr.Clients[w]; ok && c.Latest.Location.Desktop != store.Workplace.CurrentDesktop {
+				c.MoveToDesktop(uint32(store.Workplace.CurrentDesktop))
+			}
+		}
+
+		// Restore focus after window manager events
+		if common.Config.DesktopFocus {
+			time.AfterFunc(focusDelay*time.Millisecond, func() {
+				tr.Do(tr.restoreFocus)
+			})
+		}
+	}
+
+	if clientsChanged || focusChanged {
+
+		// Suspend or resume tiling for games
+		tr.handleGameMode()
 	}
 
-	if viewportChanged || clientsChanged || focusChanged {
+	if (viewportChanged || clientsChanged) && store.Idle.Active {
+
+		// Defer updates until user is active
+		tr.Handlers.Deferred.Update = true
+	} else if viewportChanged || clientsChanged || focusChanged {
 
 		// Deactivate handlers
 		tr.Handlers.Reset()
@@ -546,52 +1393,284 @@ func (tr *Tracker) onStateUpdate(state string, desktop uint, screen uint) {
 
 	if focusChanged {
 
+		// Remember focused client
+		tr.storeFocus()
+		tr.statsFocus()
+
 		// Write client and workspace cache
+		tr.WriteDelayed()
+	}
+}
+
+func (tr *Tracker) storeFocus() {
+	c := tr.ActiveClient()
+	ws := tr.ClientWorkspace(c)
+	if ws == nil {
+		return
+	}
+
+	// Move client to end of workspace focus history
+	history := []Focus{}
+	for _, f := range tr.Focused[ws.Location] {
+		if f.Window != c.Window.Id && tr.isTracked(f.Window) {
+			history = append(history, f)
+		}
+	}
+	history = append(history, Focus{Window: c.Window.Id, Time: time.Now().UnixMilli()})
+
+	// Drop oldest entries
+	if len(history) > focusLimit {
+		history = history[len(history)-focusLimit:]
+	}
+	tr.Focused[ws.Location] = history
+}
+
+func (tr *Tracker) FocusHistory(ws *Workspace) []Focus {
+	history := []Focus{}
+
+	// Obtain tracked clients of workspace (newest first)
+	focused := tr.Focused[ws.Location]
+	for i := len(focused) - 1; i >= 0; i-- {
+		if c, ok := tr.Clients[focused[i].Window]; ok && tr.ClientWorkspace(c) == ws {
+			history = append(history, focused[i])
+		}
+	}
+
+	return history
+}
+
+func (tr *Tracker) restoreFocus() {
+	ws := tr.ActiveWorkspace()
+	if ws == nil {
+		return
+	}
+
+	// Check if window manager focused a window on current desktop
+	active := store.Windows.Active.Id
+	if c, ok := tr.Clients[active]; ok {
+		if c.Latest.Location.Desktop == ws.Location.Desktop || store.IsSticky(c.Latest) {
+			return
+		}
+	} else if active != 0 && active != store.X.RootWin() && !common.IsInList("_NET_WM_WINDOW_TYPE_DESKTOP", store.GetInfo(active).Types) {
+		return
+	}
+
+	// Activate last focused client of workspace
+	history := tr.FocusHistory(ws)
+	if len(history) == 0 {
+		return
+	}
+	c := tr.Clients[history[0].Window]
+	log.Info("Restore focus of window [", c.Latest.Class, "]")
+
+	store.ActiveWindowSet(store.X, c.Window)
+}
+
+func (tr *Tracker) onIdleUpdate(idle store.XIdle) {
+	if idle.Active {
+		return
+	}
+
+	// Update trackable clients
+	if tr.Handlers.Deferred.Update {
+		tr.Handlers.Deferred.Update = false
+		tr.Update()
+	}
+
+	// Write client and workspace cache
+	if tr.Handlers.Deferred.Write {
+		tr.Handlers.Deferred.Write = false
 		tr.Write()
 	}
 }
 
-func (tr *Tracker) onPointerUpdate(pointer store.XPointer, desktop uint, screen uint) {
-	buttonReleased := !pointer.Pressed()
+func (tr *Tracker) onPropertyUpdate(w xproto.Window, atom string) {
+	delete(tr.Rejected, w)
+	if !common.IsInList(atom, []string{"WM_CLASS", "WM_NAME", "_NET_WM_NAME", "_NET_WM_WINDOW_TYPE", "_NET_WM_STATE"}) {
+		return
+	}
 
-	// Reset timer
-	if tr.Handlers.Timer != nil {
-		tr.Handlers.Timer.Stop()
+	// Re-evaluate window on next update
+	delete(tr.Trackable, w)
+
+	// Re-evaluate title rules after the title has settled
+	if common.IsInList(atom, []string{"WM_NAME", "_NET_WM_NAME"}) && len(common.Config.WindowTitle) > 0 {
+		if timer, ok := titleTimers[w]; ok {
+			timer.Stop()
+		}
+		titleTimers[w] = time.AfterFunc(titleDelay*time.Millisecond, func() {
+			tr.Do(func() { tr.onTitleUpdate(w) })
+		})
+	}
+}
+
+func (tr *Tracker) onTitleUpdate(w xproto.Window) {
+	delete(titleTimers, w)
+
+	// Skip unchanged title rule matches
+	info := store.GetInfo(w)
+	title := store.TitleGet(info)
+	entry := ""
+	if title != nil {
+		entry = title.Entry
+	}
+	if tr.Titled[w] == entry {
+		return
+	}
+	if title == nil {
+		delete(tr.Titled, w)
+	} else {
+		tr.Titled[w] = entry
+	}
+	log.Info("Window title rule changed to \"", entry, "\" [", info.Class, "]")
+
+	// Float or track window
+	delete(tr.Trackable, w)
+	tr.Update()
+	if title == nil {
+		return
+	}
+
+	// Move window to desktop and screen
+	c, tracked := tr.Clients[w]
+	if title.Desktop >= 0 && uint(title.Desktop) != info.Location.Desktop {
+		if tracked {
+			c.MoveToDesktop(uint32(title.Desktop))
+		} else {
+			store.MoveWindowToDesktop(w, uint32(title.Desktop))
+		}
+	}
+	if title.Screen >= 0 && uint(title.Screen) != info.Location.Screen {
+		if tracked && uint(title.Screen) < store.Workplace.ScreenCount {
+			c.MoveToScreen(uint32(title.Screen))
+		} else {
+			store.MoveWindowToScreen(w, uint32(title.Screen))
+		}
+	}
+}
+
+func (tr *Tracker) onErrorUpdate(w xproto.Window) {
+
+	// Untrack client of failed request
+	tr.reapWindow(w)
+}
+
+func (tr *Tracker) reapWindow(w xproto.Window) {
+	if !tr.isTracked(w) {
+		return
 	}
 
+	// Untrack client without waiting for client list events
+	log.Info("Reap closed window [", tr.Clients[w].Latest.Class, "]")
+	tr.Trackable[w] = false
+	tr.untrackWindow(w)
+}
+
+func (tr *Tracker) onPointerUpdate(pointer store.XPointer, desktop uint, screen uint) {
+	buttonReleased := !pointer.Pressed()
+
 	// Wait on button release
 	var t time.Duration = 0
 	if buttonReleased {
 		t = 50
 	}
 
+	// Release handlers after structure events
+	tr.releaseDelayed(t, buttonReleased)
+}
+
+func (tr *Tracker) onMoveResizeUpdate(w xproto.Window, direction uint32) {
+	c, ok := tr.Clients[w]
+	if !ok {
+		return
+	}
+
+	switch direction {
+	case store.MoveResizeMoveKeyboard, store.MoveResizeSizeKeyboard:
+
+		// Treat keyboard move/resize like pointer drags
+		tr.Handlers.KeyboardMove = &Handler{Dragging: true, Source: c}
+		log.Debug("Client keyboard move handler active [", c.Latest.Class, "]")
+	case store.MoveResizeCancel:
+		if !tr.keyboardMoved(c) {
+			return
+		}
+
+		// Release cancelled keyboard move/resize
+		tr.releaseDelayed(0, true)
+	}
+}
+
+func (tr *Tracker) releaseDelayed(t time.Duration, released bool) {
+
+	// Reset timer
+	if tr.Handlers.Timer != nil {
+		tr.Handlers.Timer.Stop()
+	}
+
 	// Wait for structure events
 	tr.Handlers.Timer = time.AfterFunc(t*time.Millisecond, func() {
+		tr.Do(func() { tr.release(released) })
+	})
+}
 
-		// Window moved to another screen
-		if tr.Handlers.SwapScreen.Active() {
-			tr.handleWorkspaceChange(tr.Handlers.SwapScreen)
-		}
+func (tr *Tracker) release(released bool) {
+	if released {
+		tr.Handlers.KeyboardMove.Reset()
+	}
 
-		// Window moved over another window
-		if tr.Handlers.SwapClient.Active() {
-			tr.handleSwapClient(tr.Handlers.SwapClient)
-		}
+	swapped := tr.Handlers.SwapScreen.Active() || tr.Handlers.SwapClient.Active()
+
+	// Window moved to another screen
+	if tr.Handlers.SwapScreen.Active() {
+		tr.handleWorkspaceChange(tr.Handlers.SwapScreen)
+	}
 
-		// Window moved or resized
-		if tr.Handlers.MoveClient.Active() || tr.Handlers.ResizeClient.Active() {
-			tr.Handlers.MoveClient.Reset()
-			tr.Handlers.ResizeClient.Reset()
+	// Window moved over another window
+	if tr.Handlers.SwapClient.Active() {
+		tr.handleSwapClient(tr.Handlers.SwapClient)
+	}
 
-			// Unlock clients
-			tr.unlockClients()
+	// Window dropped outside of any tile
+	if released && !swapped && tr.Handlers.MoveClient.Active() {
+		tr.handleFloatClient(tr.Handlers.MoveClient)
+	}
 
-			// Tile workspace
-			if buttonReleased {
-				tr.Tile(tr.ActiveWorkspace())
-			}
+	// Window moved or resized
+	if tr.Handlers.MoveClient.Active() || tr.Handlers.ResizeClient.Active() {
+		tr.Handlers.MoveClient.Reset()
+		tr.Handlers.ResizeClient.Reset()
+
+		// Unlock clients
+		tr.unlockClients()
+
+		// Tile workspace
+		if released {
+			tr.Tile(tr.ActiveWorkspace())
 		}
-	})
+	}
+}
+
+func (tr *Tracker) detectKeyboardMove(c *store.Client, pt *store.XPointer) {
+	if pt.Pressed() || tr.keyboardMoved(c) || c.Window.Id != store.Windows.Active.Id {
+		tr.Handlers.Stepped = Step{}
+		return
+	}
+
+	// Wait for consecutive steps of the active window without pressed buttons
+	previous := tr.Handlers.Stepped
+	tr.Handlers.Stepped = Step{Source: c, Time: time.Now()}
+	if previous.Source != c || time.Since(previous.Time) > stepDelay*time.Millisecond {
+		return
+	}
+
+	// Treat window manager internal keyboard move/resize like pointer drags
+	tr.Handlers.KeyboardMove = &Handler{Dragging: true, Source: c}
+	log.Debug("Client keyboard move handler detected [", c.Latest.Class, "]")
+}
+
+func (tr *Tracker) keyboardMoved(c *store.Client) bool {
+	return tr.Handlers.KeyboardMove.Active() && tr.Handlers.KeyboardMove.Source == c
 }
 
 func (tr *Tracker) attachHandlers(c *store.Client) {
@@ -601,29 +1680,152 @@ func (tr *Tracker) attachHandlers(c *store.Client) {
 	xevent.ConfigureNotifyFun(func(X *xgbutil.XUtil, ev xevent.ConfigureNotifyEvent) {
 		log.Trace("Client structure event [", c.Latest.Class, "]")
 
-		// Handle structure events
-		tr.handleResizeClient(c)
-		tr.handleMoveClient(c)
-		if !tr.Handlers.MoveClient.Active() {
-			c.Update()
-		}
+		// Handle structure events (ignore intermediate animation steps)
+		tr.Do(func() {
+			tr.handleResizeClient(c)
+			tr.handleMoveClient(c)
+			if !tr.Handlers.MoveClient.Active() {
+				c.Update()
+			}
+
+			// Release keyboard move/resize after inactivity
+			if tr.keyboardMoved(c) {
+				tr.releaseDelayed(1500, true)
+			}
+		})
+	}).Connect(store.X, c.Window.Id)
+
+	// Attach destroy events
+	xevent.DestroyNotifyFun(func(X *xgbutil.XUtil, ev xevent.DestroyNotifyEvent) {
+		log.Trace("Client destroy event [", c.Latest.Class, "]")
+
+		// Reap destroyed client
+		store.Guard(c.Window.Id)
+		tr.Do(func() { tr.reapWindow(c.Window.Id) })
+	}).Connect(store.X, c.Window.Id)
+
+	// Attach unmap events
+	xevent.UnmapNotifyFun(func(X *xgbutil.XUtil, ev xevent.UnmapNotifyEvent) {
+		log.Trace("Client unmap event [", c.Latest.Class, "]")
+
+		// Reap withdrawn client after window manager updated its state
+		time.AfterFunc(100*time.Millisecond, func() {
+			tr.Do(func() {
+				if tr.isTracked(c.Window.Id) && store.IsWithdrawn(c.Window.Id) {
+					tr.reapWindow(c.Window.Id)
+				}
+			})
+		})
 	}).Connect(store.X, c.Window.Id)
 
 	// Attach property events
+	store.WatchProperties(c.Window.Id)
 	xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
 		aname, _ := xprop.AtomName(store.X, ev.Atom)
 		log.Trace("Client property event ", aname, " [", c.Latest.Class, "]")
 
 		// Handle property events
-		if aname == "_NET_WM_STATE" {
-			tr.handleMaximizedClient(c)
-			tr.handleMinimizedClient(c)
-		} else if aname == "_NET_WM_DESKTOP" {
-			tr.handleWorkspaceChange(&Handler{Source: c, Target: tr.ActiveWorkspace()})
-		}
+		tr.Do(func() {
+			if aname == "_NET_WM_STATE" {
+				tr.handleMaximizedClient(c)
+				tr.handleMinimizedClient(c)
+			} else if aname == "_NET_WM_DESKTOP" {
+				tr.handleWorkspaceChange(&Handler{Source: c, Target: tr.ActiveWorkspace()})
+			}
+		})
 	}).Connect(store.X, c.Window.Id)
 }
 
+func (tr *Tracker) watchWindow(w xproto.Window) {
+	if !tr.isTracked(w) {
+		xwindow.New(store.X, w).Listen(xproto.EventMaskPropertyChange)
+	}
+	store.WatchProperties(w)
+}
+
+func secondHalf(p common.Point, g common.Geometry) bool {
+	center := g.Center()
+
+	// Split along the longer side of the geometry
+	if g.Width > g.Height {
+		return p.X > center.X
+	}
+	return p.Y > center.Y
+}
+
+func buffer() int {
+	return common.MaxInt(common.Config.TilingBuffer, 1)
+}
+
+func (tr *Tracker) work() {
+	work := tr.Channels.Work
+	for range work.Ready {
+
+		// Run pending functions in submission order
+		for {
+			work.Lock.Lock()
+			if len(work.Queue) == 0 {
+				work.Lock.Unlock()
+				break
+			}
+			fun := work.Queue[0]
+			work.Queue[0] = nil
+			work.Queue = work.Queue[1:]
+			work.Lock.Unlock()
+
+			fun()
+		}
+	}
+}
+
+func stackingDiff(previous []xproto.Window, current []xproto.Window) ([]xproto.Window, []xproto.Window, []xproto.Window) {
+	added, removed, restacked := []xproto.Window{}, []xproto.Window{}, []xproto.Window{}
+
+	// Index previous stacking order
+	index := make(map[xproto.Window]int)
+	for i, w := range previous {
+		index[w] = i
+	}
+
+	// Find added and restacked windows
+	order := make(map[xproto.Window]bool)
+	last := -1
+	for _, w := range current {
+		i, ok := index[w]
+		if !ok {
+			added = append(added, w)
+			continue
+		}
+		if i < last {
+			restacked = append(restacked, w)
+		}
+		last = max(last, i)
+		order[w] = true
+	}
+
+	// Find removed windows
+	for _, w := range previous {
+		if !order[w] {
+			removed = append(removed, w)
+		}
+	}
+
+	return added, removed, restacked
+}
+
+func (tr *Tracker) screens() uint {
+	screens := uint(0)
+
+	// Count workspaces on first desktop
+	for location := range tr.Workspaces {
+		if location.Desktop == 0 {
+			screens++
+		}
+	}
+
+	return screens
+}
+
 func (tr *Tracker) isTracked(w xproto.Window) bool {
 	_, ok := tr.Clients[w]
 	return ok
@@ -631,5 +1833,5 @@ func (tr *Tracker) isTracked(w xproto.Window) bool {
 
 func (tr *Tracker) isTrackable(w xproto.Window) bool {
 	info := store.GetInfo(w)
-	return !store.IsSpecial(info) && !store.IsIgnored(info)
+	return !tr.Floating[w] && !store.IsSpecial(info) && !store.IsIgnored(info)
 }
@@ -3,6 +3,8 @@ package desktop
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"encoding/json"
 	"path/filepath"
@@ -15,11 +17,16 @@ import (
 )
 
 type Workspace struct {
-	Name     string         // Workspace location name
-	Location store.Location // Desktop and screen location
-	Layouts  []Layout       // List of available layouts
-	Layout   uint           // Active layout index
-	Tiling   bool           // Tiling is enabled
+	Name      string         // Workspace location name
+	Alias     string         // Workspace user defined name
+	Location  store.Location // Desktop and screen location
+	Layouts   []Layout       // List of available layouts
+	Layout    uint           // Active layout index
+	Tiling    bool           // Tiling is enabled
+	Locked    bool           // Layout is locked
+	Suspended bool           `json:"-"` // Tiling is suspended by game mode
+	Paused    int64          `json:"-"` // Tiling paused until timestamp
+	Timer     *time.Timer    `json:"-"` // Timer to resume paused tiling
 }
 
 func CreateWorkspaces() map[store.Location]*Workspace {
@@ -29,46 +36,61 @@ func CreateWorkspaces() map[store.Location]*Workspace {
 		for screen := uint(0); screen < store.Workplace.ScreenCount; screen++ {
 			location := store.Location{Desktop: desktop, Screen: screen}
 
-			// Create layouts for each desktop and screen
-			ws := &Workspace{
-				Name:     fmt.Sprintf("workspace-%d-%d", location.Desktop, location.Screen),
-				Location: location,
-				Layouts:  CreateLayouts(location),
-				Layout:   0,
-				Tiling:   common.Config.TilingEnabled,
-			}
+			// Map location to workspace
+			workspaces[location] = CreateWorkspace(location)
+		}
+	}
 
-			// Set default layout
-			for i, l := range ws.Layouts {
-				if l.GetName() == common.Config.TilingLayout {
-					ws.SetLayout(uint(i))
-				}
-			}
+	return workspaces
+}
 
-			// Read workspace from cache
-			cached := ws.Read()
-
-			// Overwrite default layout, proportions, decoration and tiling state
-			ws.SetLayout(cached.Layout)
-			for _, l := range ws.Layouts {
-				for _, cl := range cached.Layouts {
-					if l.GetName() == cl.GetName() {
-						mg, cmg := l.GetManager(), cl.GetManager()
-						mg.Masters.Maximum = common.MinInt(cmg.Masters.Maximum, common.Config.WindowMastersMax)
-						mg.Slaves.Maximum = common.MinInt(cmg.Slaves.Maximum, common.Config.WindowSlavesMax)
-						mg.Proportions = cmg.Proportions
-						mg.Decoration = cmg.Decoration
-					}
-				}
-			}
-			ws.Tiling = cached.Tiling
+func CreateWorkspace(location store.Location) *Workspace {
 
-			// Map location to workspace
-			workspaces[location] = ws
+	// Create layouts for desktop and screen
+	ws := &Workspace{
+		Name:     fmt.Sprintf("workspace-%d-%d", location.Desktop, location.Screen),
+		Location: location,
+		Layouts:  CreateLayouts(location),
+		Layout:   0,
+		Tiling:   common.Config.TilingEnabled,
+	}
+
+	// Set default layout
+	for i, l := range ws.Layouts {
+		if l.GetName() == common.Config.TilingLayout {
+			ws.SetLayout(uint(i))
 		}
 	}
 
-	return workspaces
+	// Read workspace from cache
+	ws.Apply(ws.Read())
+
+	// Replay workspace from journal
+	if replayed := ws.Replay(); replayed != nil {
+		ws.Apply(replayed)
+	}
+
+	return ws
+}
+
+func (ws *Workspace) Apply(cached *Workspace) {
+
+	// Overwrite default layout, proportions, decoration and tiling state
+	ws.SetLayout(cached.Layout)
+	for _, l := range ws.Layouts {
+		for _, cl := range cached.Layouts {
+			if l.GetName() == cl.GetName() {
+				mg, cmg := l.GetManager(), cl.GetManager()
+				mg.Masters.Maximum = common.MinInt(cmg.Masters.Maximum, common.Config.WindowMastersMax)
+				mg.Slaves.Maximum = common.MinInt(cmg.Slaves.Maximum, common.Config.WindowSlavesMax)
+				mg.Proportions = cmg.Proportions
+				mg.Decoration = cmg.Decoration
+			}
+		}
+	}
+	ws.Tiling = cached.Tiling
+	ws.Locked = cached.Locked
+	ws.Alias = cached.Alias
 }
 
 func CreateLayouts(loc store.Location) []Layout {
@@ -84,6 +106,7 @@ func CreateLayouts(loc store.Location) []Layout {
 
 func (ws *Workspace) EnableTiling() {
 	ws.Tiling = true
+	ws.Suspended = false
 }
 
 func (ws *Workspace) DisableTiling() {
@@ -94,14 +117,81 @@ func (ws *Workspace) TilingEnabled() bool {
 	if ws == nil {
 		return false
 	}
-	return ws.Tiling
+	return ws.Tiling && !ws.Suspended && ws.Paused == 0 && store.ScreenTileable(ws.Location.Screen)
 }
 
 func (ws *Workspace) TilingDisabled() bool {
 	if ws == nil {
 		return true
 	}
-	return !ws.Tiling
+	return !ws.Tiling || ws.Suspended || ws.Paused != 0 || !store.ScreenTileable(ws.Location.Screen)
+}
+
+func (ws *Workspace) Lock() {
+	log.Info("Lock layout [", ws.Name, "]")
+
+	ws.Locked = true
+}
+
+func (ws *Workspace) UnLock() {
+	log.Info("Unlock layout [", ws.Name, "]")
+
+	ws.Locked = false
+}
+
+func (ws *Workspace) Pause(d time.Duration, do func(func()), fun func()) {
+	ws.Unpause()
+
+	log.Info("Pause tiling for ", d, " [", ws.Name, "]")
+
+	// Resume tiling after given duration
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		do(func() {
+			if ws.Timer != timer {
+				return
+			}
+			ws.Paused = 0
+			ws.Timer = nil
+			fun()
+		})
+	})
+	ws.Paused = time.Now().Add(d).UnixMilli()
+	ws.Timer = timer
+}
+
+func (ws *Workspace) Unpause() bool {
+	if ws.Timer == nil {
+		return false
+	}
+
+	// Stop resume timer
+	ws.Timer.Stop()
+	ws.Timer = nil
+	ws.Paused = 0
+
+	return true
+}
+
+func (ws *Workspace) PausedFor() time.Duration {
+	if ws == nil || ws.Paused == 0 {
+		return 0
+	}
+	return time.Until(time.UnixMilli(ws.Paused))
+}
+
+func (ws *Workspace) Rename(name string) {
+	log.Info("Rename workspace to \"", name, "\" [", ws.Name, "]")
+
+	ws.Alias = strings.TrimSpace(name)
+}
+
+func (ws *Workspace) HasName(name string) bool {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return false
+	}
+	return ws.Name == name || (len(ws.Alias) > 0 && strings.EqualFold(ws.Alias, name))
 }
 
 func (ws *Workspace) ActiveLayout() Layout {
@@ -204,7 +294,7 @@ func (ws *Workspace) Tile() {
 		if c == nil {
 			continue
 		}
-		if mg.DecorationEnabled() {
+		if c.Decoration(mg.DecorationEnabled()) {
 			if c.Decorate() {
 				c.Update()
 			}
@@ -216,7 +306,70 @@ func (ws *Workspace) Tile() {
 	}
 
 	// Apply active layout
+	store.BeginMoves()
 	ws.ActiveLayout().Apply()
+	store.CommitMoves()
+
+	// Apply stacking order
+	ws.Stack()
+}
+
+func (ws *Workspace) Stack() {
+	al := ws.ActiveLayout()
+	mg := al.GetManager()
+
+	// Obtain stacking order of active layout
+	order := ""
+	for _, s := range common.Config.WindowStacking {
+		if len(s) == 2 && (s[0] == al.GetName() || (s[0] == "all" && len(order) == 0)) {
+			order = s[1]
+		}
+	}
+
+	// Raise lower clients before upper clients
+	var lower, upper []*store.Client
+	switch order {
+	case "masters":
+		lower, upper = mg.Ordered(mg.Slaves), mg.Ordered(mg.Masters)
+	case "slaves":
+		lower, upper = mg.Ordered(mg.Masters), mg.Ordered(mg.Slaves)
+	case "":
+		return
+	default:
+		log.Warn("Invalid stacking order \"", order, "\" [", ws.Name, "]")
+		return
+	}
+
+	// Keep active window on top
+	ordered := []*store.Client{}
+	var active *store.Client
+	for _, c := range append(lower, upper...) {
+		if c == nil {
+			continue
+		}
+		if c.Window.Id == store.Windows.Active.Id {
+			active = c
+			continue
+		}
+		ordered = append(ordered, c)
+	}
+	if active != nil {
+		ordered = append(ordered, active)
+	}
+
+	// Obtain current stacking order of clients
+	current := mg.Ordered(&store.Clients{Stacked: ordered})
+
+	// Raise clients from the first misplaced one upwards
+	for i, c := range ordered {
+		if i < len(current) && current[i] == c {
+			continue
+		}
+		for _, c := range ordered[i:] {
+			c.Raise()
+		}
+		break
+	}
 }
 
 func (ws *Workspace) Restore(flag uint8) {
@@ -234,7 +387,7 @@ func (ws *Workspace) Restore(flag uint8) {
 	}
 }
 
-func (ws *Workspace) Write() {
+func (ws *Workspace) Write(batch *common.CacheBatch) {
 	if common.CacheDisabled() {
 		return
 	}
@@ -243,19 +396,15 @@ func (ws *Workspace) Write() {
 	cache := ws.Cache()
 
 	// Parse workspace cache
-	data, err := json.MarshalIndent(cache.Data, "", "  ")
+	data, err := common.MarshalCache(cache.Data)
 	if err != nil {
 		log.Warn("Error parsing workspace cache [", ws.Name, "]")
 		return
 	}
 
-	// Write workspace cache
+	// Add workspace cache to batch
 	path := filepath.Join(cache.Folder, cache.Name)
-	err = os.WriteFile(path, data, 0644)
-	if err != nil {
-		log.Warn("Error writing workspace cache [", ws.Name, "]")
-		return
-	}
+	batch.Add(path, data)
 
 	log.Trace("Write workspace cache data ", cache.Name, " [", ws.Name, "]")
 }
@@ -270,12 +419,19 @@ func (ws *Workspace) Read() *Workspace {
 
 	// Read workspace cache
 	path := filepath.Join(cache.Folder, cache.Name)
-	data, err := os.ReadFile(path)
+	data, err := common.ReadCache(path)
 	if os.IsNotExist(err) {
 		log.Info("No workspace cache found [", ws.Name, "]")
 		return ws
 	}
 
+	// Migrate workspace cache
+	data, err = common.MigrateCache(path, data)
+	if err != nil {
+		log.Warn("Error migrating workspace cache [", ws.Name, "]")
+		return ws
+	}
+
 	// Parse workspace cache
 	cached := &Workspace{Layouts: CreateLayouts(ws.Location)}
 	err = json.Unmarshal([]byte(data), &cached)
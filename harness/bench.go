@@ -0,0 +1,207 @@
+package harness
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+type Report struct {
+	Windows    int        // Number of benchmarked windows
+	Iterations int        // Number of benchmark iterations
+	Simulated  bool       // Benchmark without X server
+	Samples    []*Samples // Measured latencies per operation
+}
+
+type Samples struct {
+	Name      string          // Operation name
+	Durations []time.Duration // Measured latencies
+}
+
+func Bench(windows int, iterations int, simulate bool, config []byte) (*Report, error) {
+	report := &Report{Windows: windows, Iterations: iterations, Simulated: simulate}
+
+	// Run benchmark
+	if simulate {
+		benchSimulation(report)
+		return report, nil
+	}
+
+	return report, benchServer(report, config)
+}
+
+func (r *Report) Add(name string) *Samples {
+	samples := &Samples{Name: name}
+	r.Samples = append(r.Samples, samples)
+	return samples
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+
+	mode := "xvfb"
+	if r.Simulated {
+		mode = "simulation"
+	}
+	fmt.Fprintf(&b, "BENCH: \n  mode: %s\n  windows: %d\n  iterations: %d\n", mode, r.Windows, r.Iterations)
+
+	// Latency distribution per operation
+	for _, s := range r.Samples {
+		fmt.Fprintf(&b, "  %s:\n", strings.ToLower(s.Name))
+		fmt.Fprintf(&b, "    min: %s\n    p50: %s\n    p90: %s\n    p99: %s\n    max: %s\n    mean: %s\n",
+			s.Percentile(0), s.Percentile(50), s.Percentile(90), s.Percentile(99), s.Percentile(100), s.Mean())
+	}
+
+	return b.String()
+}
+
+func (s *Samples) Measure(fun func()) {
+	start := time.Now()
+	fun()
+	s.Durations = append(s.Durations, time.Since(start))
+}
+
+func (s *Samples) Percentile(p float64) time.Duration {
+	if len(s.Durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, s.Durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// Nearest rank percentile
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[common.MaxInt(common.MinInt(rank, len(sorted)-1), 0)]
+}
+
+func (s *Samples) Mean() time.Duration {
+	if len(s.Durations) == 0 {
+		return 0
+	}
+	sum := time.Duration(0)
+	for _, d := range s.Durations {
+		sum += d
+	}
+	return sum / time.Duration(len(s.Durations))
+}
+
+func benchSimulation(report *Report) {
+	geom := common.Geometry{X: 0, Y: 0, Width: 1920, Height: 1080}
+	loc := store.Location{Desktop: 0, Screen: 0}
+
+	// Simulate window requests without X server
+	common.Args.DryRun = true
+	store.WindowManager = &store.XWindowManager{Name: "simulation"}
+	store.Workplace = &store.XWorkplace{
+		DesktopCount: 1,
+		ScreenCount:  1,
+		Displays: store.XDisplays{
+			Name:     "simulation",
+			Screens:  []store.XHead{{Primary: true, Scale: 1.0, Geometry: geom}},
+			Desktops: []store.XHead{{Primary: true, Scale: 1.0, Geometry: geom}},
+		},
+	}
+
+	// Create fake clients
+	clients := make([]*store.Client, report.Windows)
+	for i := range clients {
+		info := &store.Info{
+			Class:    fmt.Sprintf("bench-%d", i),
+			Name:     fmt.Sprintf("bench-%d", i),
+			Types:    []string{"_NET_WM_WINDOW_TYPE_NORMAL"},
+			Location: loc,
+			Dimensions: store.Dimensions{
+				Geometry: common.Geometry{X: 10 * i, Y: 10 * i, Width: 400, Height: 300},
+			},
+		}
+		original, cached := *info, *info
+		clients[i] = &store.Client{
+			Window:   &store.XWindow{Id: xproto.Window(i + 1), Created: time.Now().UnixMilli()},
+			Original: &original,
+			Cached:   &cached,
+			Latest:   info,
+		}
+	}
+
+	// Tracker updates need an X server, measure workspace insertion instead
+	add := report.Add("AddClient")
+	tile := report.Add("Tile")
+	for i := 0; i < report.Iterations; i++ {
+		ws := &desktop.Workspace{Name: "bench", Location: loc, Layouts: desktop.CreateLayouts(loc), Tiling: true}
+
+		// Measure workspace insertion
+		add.Measure(func() {
+			for _, c := range clients {
+				ws.AddClient(c)
+			}
+		})
+
+		// Measure layout application
+		ws.SetLayout(uint(i % 4))
+		for _, c := range clients {
+			c.Moved = store.Moved{}
+		}
+		tile.Measure(func() {
+			store.BeginMoves()
+			ws.ActiveLayout().Apply()
+			store.CommitMoves()
+		})
+	}
+}
+
+func benchServer(report *Report, config []byte) error {
+	h, err := Start(Options{Config: config})
+	if err != nil {
+		return err
+	}
+	defer h.Stop()
+
+	tr, err := h.Track()
+	if err != nil {
+		return err
+	}
+
+	// Create dummy windows
+	windows := []xproto.Window{}
+	for i := 0; i < report.Windows; i++ {
+		win, err := h.CreateWindow(fmt.Sprintf("bench-%d", i), 400, 300)
+		if err != nil {
+			return err
+		}
+		windows = append(windows, win.Instance.Id)
+	}
+	if !h.Until(func(tr *desktop.Tracker) bool { return len(tr.Clients) >= report.Windows }) {
+		return fmt.Errorf("tracked only %d of %d windows", h.Tracked(), report.Windows)
+	}
+
+	update := report.Add("Update")
+	tile := report.Add("Tile")
+	info := report.Add("GetInfo")
+	for i := 0; i < report.Iterations; i++ {
+
+		// Measure tracker update
+		update.Measure(func() {
+			tr.Exec(tr.Update)
+		})
+
+		// Measure layout application
+		tr.Exec(func() {
+			ws := tr.ActiveWorkspace()
+			ws.SetLayout(uint(i % 4))
+			tile.Measure(ws.Tile)
+		})
+
+		// Measure window information requests
+		info.Measure(func() {
+			store.GetInfoBatch(windows)
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"os/exec"
+	"path/filepath"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/ewmh"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/input"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Options struct {
+	Display int      // Display number of virtual X server (0 = first free)
+	Screen  string   // Screen geometry of virtual X server
+	Manager []string // Command of EWMH compliant window manager
+	Config  []byte   // Content of config file
+	Timeout int      // Time duration to wait for startup
+}
+
+type Harness struct {
+	Options Options                   // Harness startup options
+	Display string                    // Display name of virtual X server
+	Folder  string                    // Temporary folder for config, cache, lock and log files
+	Server  *exec.Cmd                 // Virtual X server process
+	Manager *exec.Cmd                 // Window manager process
+	Conn    *xgbutil.XUtil            // Connection for dummy windows
+	Tracker *desktop.Tracker          // Tracker driven by harness
+	Windows map[xproto.Window]*Window // Created dummy windows
+}
+
+var (
+	DefaultOptions = Options{
+		Screen:  "1920x1080x24",
+		Manager: []string{"openbox"},
+		Timeout: 10000,
+	} // Options used for unset values
+)
+
+func Start(options Options) (*Harness, error) {
+	h := &Harness{Options: options, Windows: map[xproto.Window]*Window{}}
+	if len(h.Options.Screen) == 0 {
+		h.Options.Screen = DefaultOptions.Screen
+	}
+	if len(h.Options.Manager) == 0 {
+		h.Options.Manager = DefaultOptions.Manager
+	}
+	if h.Options.Timeout <= 0 {
+		h.Options.Timeout = DefaultOptions.Timeout
+	}
+
+	// Create temporary folder
+	folder, err := os.MkdirTemp("", "cortile-harness-")
+	if err != nil {
+		return nil, err
+	}
+	h.Folder = folder
+
+	// Start virtual X server
+	if h.Options.Display == 0 {
+		h.Options.Display = freeDisplay()
+	}
+	h.Display = fmt.Sprintf(":%d", h.Options.Display)
+	h.Server, err = run("Xvfb", h.Display, "-screen", "0", h.Options.Screen, "+extension", "RANDR")
+	if err != nil {
+		h.Stop()
+		return nil, err
+	}
+
+	// Connect to virtual X server
+	err = h.wait(func() bool {
+		h.Conn, err = xgbutil.NewConnDisplay(h.Display)
+		return err == nil
+	})
+	if err != nil {
+		h.Stop()
+		return nil, fmt.Errorf("connection to %s failed: %s", h.Display, err)
+	}
+	os.Setenv("DISPLAY", h.Display)
+
+	// Start window manager
+	h.Manager, err = run(h.Options.Manager[0], h.Options.Manager[1:]...)
+	if err != nil {
+		h.Stop()
+		return nil, err
+	}
+	err = h.wait(func() bool {
+		_, err = ewmh.GetEwmhWM(h.Conn)
+		return err == nil
+	})
+	if err != nil {
+		h.Stop()
+		return nil, fmt.Errorf("window manager %s not ready: %s", h.Options.Manager[0], err)
+	}
+
+	log.Info("Harness started on ", h.Display, " [", h.Options.Manager[0], "]")
+
+	return h, nil
+}
+
+func (h *Harness) Track() (*desktop.Tracker, error) {
+	if h.Tracker != nil {
+		return h.Tracker, nil
+	}
+
+	// Init process, build and source information
+	common.InitInfo("cortile", "harness", "0.0.0", "harness", "unknown", "github.com/leukipp/cortile", strings.Join([]string{
+		"disable-release-info",
+		"disable-issue-info",
+		"disable-dbus-interface",
+		"disable-addons-folder",
+	}, ","))
+
+	// Init arguments and files
+	common.Args.Cache = filepath.Join(h.Folder, "cache")
+	common.Args.Config = filepath.Join(h.Folder, "config.toml")
+	common.Args.Lock = filepath.Join(h.Folder, "cortile.lock")
+	common.Args.Log = filepath.Join(h.Folder, "cortile.log")
+	common.InitFiles(h.Options.Config, nil)
+	if err := os.WriteFile(common.Args.Config, h.Options.Config, 0644); err != nil {
+		return nil, err
+	}
+
+	// Init cache, config and power
+	common.InitCache()
+	common.LoadConfig()
+	common.InitPower()
+
+	// Init root properties
+	store.InitRoot()
+
+	// Create tracker instance
+	h.Tracker = desktop.CreateTracker()
+	h.Tracker.Exec(h.Tracker.Update)
+
+	// Run X event loop
+	go store.Loop()
+
+	return h.Tracker, nil
+}
+
+func (h *Harness) Execute(action string) bool {
+	success := false
+	if h.Tracker == nil {
+		return success
+	}
+
+	// Execute action on active workspace
+	h.Tracker.Exec(func() {
+		success = input.ExecuteAction(action, h.Tracker, h.Tracker.ActiveWorkspace(), "harness")
+	})
+
+	return success
+}
+
+func (h *Harness) Until(condition func(*desktop.Tracker) bool) bool {
+	if h.Tracker == nil {
+		return false
+	}
+
+	// Poll condition within tracker worker
+	return h.wait(func() bool {
+		satisfied := false
+		h.Tracker.Exec(func() {
+			satisfied = condition(h.Tracker)
+		})
+		return satisfied
+	}) == nil
+}
+
+func (h *Harness) Tracked() int {
+	if h.Tracker == nil {
+		return 0
+	}
+
+	// Count clients within tracker worker
+	tracked := 0
+	h.Tracker.Exec(func() {
+		tracked = len(h.Tracker.Clients)
+	})
+
+	return tracked
+}
+
+func (h *Harness) Resize(width, height int) error {
+	cmd := exec.Command("xrandr", "--fb", fmt.Sprintf("%dx%d", width, height))
+	cmd.Env = append(os.Environ(), fmt.Sprintf("DISPLAY=%s", h.Display))
+
+	// Change virtual screen size
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error resizing screen: %s (%s)", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (h *Harness) Stop() {
+
+	// Destroy dummy windows
+	for _, win := range h.Windows {
+		win.Destroy()
+	}
+
+	// Close connection
+	if h.Conn != nil {
+		h.Conn.Conn().Close()
+	}
+
+	// Terminate processes
+	for _, cmd := range []*exec.Cmd{h.Manager, h.Server} {
+		if cmd != nil && cmd.Process != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}
+	}
+
+	// Remove temporary folder
+	if len(h.Folder) > 0 {
+		os.RemoveAll(h.Folder)
+	}
+}
+
+func (h *Harness) wait(condition func() bool) error {
+	timeout := time.Now().Add(time.Duration(h.Options.Timeout) * time.Millisecond)
+
+	// Poll condition until timeout
+	for !condition() {
+		if time.Now().After(timeout) {
+			return fmt.Errorf("timeout after %d ms", h.Options.Timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return nil
+}
+
+func run(name string, args ...string) (*exec.Cmd, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found: %s", name, err)
+	}
+
+	// Start background process
+	cmd := exec.Command(path, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting %s: %s", name, err)
+	}
+
+	return cmd, nil
+}
+
+func freeDisplay() int {
+	display := 99
+
+	// Find display without lock file
+	for {
+		if _, err := os.Stat(fmt.Sprintf("/tmp/.X%d-lock", display)); os.IsNotExist(err) {
+			return display
+		}
+		display++
+	}
+}
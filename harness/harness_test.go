@@ -0,0 +1,111 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+func TestTile(t *testing.T) {
+	for _, name := range append([]string{"Xvfb"}, DefaultOptions.Manager[0]) {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available", name)
+		}
+	}
+
+	// Start harness with default config
+	config, err := os.ReadFile("../config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := Start(Options{Config: config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Stop()
+
+	tr, err := h.Track()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create dummy windows
+	windows := []*Window{}
+	for i := 0; i < 3; i++ {
+		win, err := h.CreateWindow(fmt.Sprintf("tile-%d", i), 400, 300)
+		if err != nil {
+			t.Fatal(err)
+		}
+		windows = append(windows, win)
+	}
+	if !h.Until(func(tr *desktop.Tracker) bool { return len(tr.Clients) == len(windows) }) {
+		t.Fatalf("tracked %d of %d windows", h.Tracked(), len(windows))
+	}
+
+	// Tile windows with each layout
+	for _, layout := range []string{"vertical_left", "vertical_right", "horizontal_top", "horizontal_bottom"} {
+		t.Run(layout, func(t *testing.T) {
+			if !h.Execute("layout_" + layout) {
+				t.Fatal("action failed")
+			}
+			if !h.Until(func(tr *desktop.Tracker) bool { return tiled(windows, tr) }) {
+				t.Fatal(describe(windows))
+			}
+		})
+	}
+
+	// Swap master with slave window
+	t.Run("master_swap", func(t *testing.T) {
+		var master *store.Client
+		tr.Exec(func() {
+			master = tr.ActiveWorkspace().ActiveLayout().GetManager().Masters.Stacked[0]
+		})
+		if !h.Execute("master_swap") {
+			t.Fatal("action failed")
+		}
+		if !h.Until(func(tr *desktop.Tracker) bool {
+			return !tr.ActiveWorkspace().ActiveLayout().GetManager().IsMaster(master) && tiled(windows, tr)
+		}) {
+			t.Fatal(describe(windows))
+		}
+	})
+}
+
+func tiled(windows []*Window, tr *desktop.Tracker) bool {
+	dim := store.DesktopGeometry(tr.ActiveWorkspace().Location.Screen)
+
+	// Check windows inside desktop without overlaps
+	geoms := []common.Geometry{}
+	for _, win := range windows {
+		geom, err := win.Geometry()
+		if err != nil || geom.Width == 0 || geom.Height == 0 {
+			return false
+		}
+		if geom.X < dim.X || geom.Y < dim.Y || geom.X+geom.Width > dim.X+dim.Width || geom.Y+geom.Height > dim.Y+dim.Height {
+			return false
+		}
+		for _, other := range geoms {
+			if geom.X < other.X+other.Width && other.X < geom.X+geom.Width && geom.Y < other.Y+other.Height && other.Y < geom.Y+geom.Height {
+				return false
+			}
+		}
+		geoms = append(geoms, geom)
+	}
+
+	return true
+}
+
+func describe(windows []*Window) string {
+	txt := fmt.Sprint("windows not tiled within ", *store.DesktopGeometry(0), ":")
+	for _, win := range windows {
+		geom, _ := win.Geometry()
+		txt += fmt.Sprint(" ", win.Class, "=", geom)
+	}
+
+	return txt
+}
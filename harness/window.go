@@ -0,0 +1,67 @@
+package harness
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Window struct {
+	Class    string          // Window class name
+	Instance *xwindow.Window // Dummy window instance
+}
+
+func (h *Harness) CreateWindow(class string, width, height int) (*Window, error) {
+	instance, err := xwindow.Generate(h.Conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create dummy window
+	err = instance.CreateChecked(h.Conn.RootWin(), 0, 0, width, height, xproto.CwBackPixel, 0xffffff)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set window properties
+	icccm.WmClassSet(h.Conn, instance.Id, &icccm.WmClass{Instance: class, Class: class})
+	icccm.WmNameSet(h.Conn, instance.Id, class)
+	ewmh.WmNameSet(h.Conn, instance.Id, class)
+	ewmh.WmWindowTypeSet(h.Conn, instance.Id, []string{"_NET_WM_WINDOW_TYPE_NORMAL"})
+
+	// Map window
+	instance.Map()
+	h.Conn.Sync()
+
+	win := &Window{Class: class, Instance: instance}
+	h.Windows[instance.Id] = win
+
+	return win, nil
+}
+
+func (h *Harness) DestroyWindow(win *Window) {
+	delete(h.Windows, win.Instance.Id)
+	win.Destroy()
+	h.Conn.Sync()
+}
+
+func (w *Window) Geometry() (common.Geometry, error) {
+	geom, err := w.Instance.DecorGeometry()
+	if err != nil {
+		return common.Geometry{}, err
+	}
+
+	return common.Geometry{X: geom.X(), Y: geom.Y(), Width: geom.Width(), Height: geom.Height()}, nil
+}
+
+func (w *Window) Activate() error {
+	return ewmh.ActiveWindowReq(w.Instance.X, w.Instance.Id)
+}
+
+func (w *Window) Destroy() {
+	w.Instance.Destroy()
+}
@@ -1,12 +1,17 @@
 package input
 
 import (
+	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"encoding/json"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/jezek/xgbutil/xevent"
 
@@ -22,35 +27,113 @@ var (
 	executeCallbacksFun []func(string, uint, uint) // Execute events callback functions
 )
 
+var (
+	lockedActions = []string{
+		"reset",
+		"cycle_next",
+		"cycle_previous",
+		"layout_vertical_left",
+		"layout_vertical_right",
+		"layout_horizontal_top",
+		"layout_horizontal_bottom",
+		"layout_maximized",
+		"layout_fullscreen",
+		"slave_increase",
+		"slave_decrease",
+		"master_increase",
+		"master_decrease",
+		"master_make",
+		"master_make_next",
+		"master_make_previous",
+		"master_swap",
+		"masters_cycle",
+		"window_swap_select",
+		"window_shift_up",
+		"window_shift_down",
+		"window_promote",
+		"window_demote",
+		"proportion_increase",
+		"proportion_decrease",
+		"window_proportion_increase",
+		"window_proportion_decrease",
+		"proportion_set",
+		"proportions_equalize",
+		"proportions_reset",
+		"presentation",
+		"window_pin",
+		"selection_float",
+		"selection_group",
+		"selection_to_desktop",
+		"selection_to_screen",
+	} // Actions rejected on locked workspaces
+)
+
 func Bind(tr *desktop.Tracker) {
 	BindSignal(tr)
 	BindMouse(tr)
 	BindKeys(tr)
 	BindTray(tr)
+	BindIndicator(tr)
 	BindDbus(tr)
 	BindAddons(tr)
 }
 
-func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bool {
+func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace, source string) bool {
 	success := false
 	if len(action) == 0 || tr == nil || ws == nil {
 		return false
 	}
 
-	log.Info("Execute action ", action, " [", ws.Name, "]")
+	log.Info("Execute action ", action, " from ", source, " [", ws.Name, "]")
+
+	// Split action arguments
+	name, args, _ := strings.Cut(action, " ")
+
+	// Reject layout actions on locked workspaces
+	if ws.Locked && common.IsInList(name, lockedActions) {
+		log.Warn("Reject action ", name, " on locked layout [", ws.Name, "]")
+		tr.Audit.Push(source, action, ws, false)
+		ui.ShowLayout(ws)
+		return false
+	}
+
+	// Reject layout actions while windows are dragged
+	if tr.Handlers.Dragging() && common.IsInList(name, lockedActions) {
+		log.Warn("Reject action ", name, " while dragging [", ws.Name, "]")
+		tr.Audit.Push(source, action, ws, false)
+		return false
+	}
 
 	// Choose action command
-	switch action {
+	switch name {
 	case "enable":
 		success = EnableTiling(tr, ws)
 	case "disable":
 		success = DisableTiling(tr, ws)
 	case "toggle":
 		success = ToggleTiling(tr, ws)
+	case "tiling_enable_all":
+		success = EnableTilingAll(tr, ws)
+	case "tiling_disable_all":
+		success = DisableTilingAll(tr, ws)
+	case "layout_set_all":
+		success = SetLayoutAll(tr, ws, args)
+	case "lock":
+		success = ToggleLock(tr, ws)
+	case "pause":
+		success = PauseTiling(tr, ws)
 	case "decoration":
 		success = ToggleDecoration(tr, ws)
+	case "window_decoration":
+		success = ToggleClientDecoration(tr, ws)
+	case "window_calibrate":
+		success = CalibrateWindow(tr, ws)
+	case "window_pin":
+		success = TogglePinClient(tr, ws)
 	case "restore":
 		success = Restore(tr, ws)
+	case "primary":
+		success = TogglePrimary(tr, ws)
 	case "reset":
 		success = Reset(tr, ws)
 	case "cycle_next":
@@ -81,6 +164,20 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = NextWindow(tr, ws)
 	case "window_previous":
 		success = PreviousWindow(tr, ws)
+	case "desktop_back_and_forth":
+		success = DesktopBackAndForth(tr, ws)
+	case "desktop_add":
+		success = AddDesktop(tr, ws)
+	case "desktop_remove":
+		success = RemoveDesktop(tr, ws)
+	case "desktop_next_occupied":
+		success = NextOccupiedDesktop(tr, ws)
+	case "desktop_prev_occupied":
+		success = PreviousOccupiedDesktop(tr, ws)
+	case "focus_empty_desktop":
+		success = FocusEmptyDesktop(tr, ws)
+	case "window_to_empty_desktop":
+		success = WindowToEmptyDesktop(tr, ws)
 	case "screen_next":
 		success = NextScreen(tr, ws)
 	case "screen_previous":
@@ -91,10 +188,58 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = MakeMasterNext(tr, ws)
 	case "master_make_previous":
 		success = MakeMasterPrevious(tr, ws)
+	case "master_swap":
+		success = SwapMaster(tr, ws)
+	case "window_swap_select":
+		success = SelectSwapTarget(tr, ws)
+	case "masters_cycle":
+		success = CycleMasters(tr, ws)
+	case "window_ignore_class":
+		success = IgnoreWindowClass(tr, ws)
+	case "window_unignore_class":
+		success = UnignoreWindowClass(tr, ws)
+	case "window_select":
+		success = SelectWindow(tr, ws)
+	case "window_select_pointer":
+		success = SelectPointerWindow(tr, ws)
+	case "selection_clear":
+		success = ClearSelection(tr, ws)
+	case "selection_float":
+		success = FloatSelection(tr, ws)
+	case "selection_group":
+		success = GroupSelection(tr, ws)
+	case "selection_to_desktop":
+		success = SelectionToDesktop(tr, ws, args)
+	case "selection_to_screen":
+		success = SelectionToScreen(tr, ws, args)
+	case "window_shift_up":
+		success = ShiftWindowUp(tr, ws)
+	case "window_shift_down":
+		success = ShiftWindowDown(tr, ws)
+	case "window_promote":
+		success = PromoteWindow(tr, ws)
+	case "window_demote":
+		success = DemoteWindow(tr, ws)
 	case "proportion_increase":
-		success = IncreaseProportion(tr, ws)
+		success = IncreaseProportion(tr, ws, args)
 	case "proportion_decrease":
-		success = DecreaseProportion(tr, ws)
+		success = DecreaseProportion(tr, ws, args)
+	case "window_proportion_increase":
+		success = IncreaseClientProportion(tr, ws, args)
+	case "window_proportion_decrease":
+		success = DecreaseClientProportion(tr, ws, args)
+	case "proportion_set":
+		success = SetProportion(tr, ws, args)
+	case "presentation":
+		success = TogglePresentation(tr, ws, args)
+	case "proportions_equalize":
+		success = EqualizeProportions(tr, ws)
+	case "proportions_reset":
+		success = ResetProportions(tr, ws)
+	case "state_dump":
+		success = StateDump(tr)
+	case "audit_dump":
+		success = AuditDump(tr)
 	case "restart":
 		success = Restart(tr)
 	case "exit":
@@ -102,7 +247,10 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 	default:
 		success = External(action)
 	}
-	time.AfterFunc(100*time.Millisecond, tr.Handlers.Reset)
+	time.AfterFunc(100*time.Millisecond, func() { tr.Do(tr.Handlers.Reset) })
+
+	// Record action
+	tr.Audit.Push(source, action, ws, success)
 
 	// Check success
 	if !success {
@@ -115,7 +263,7 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 	return true
 }
 
-func ExecuteActions(action string, tr *desktop.Tracker, mod string) bool {
+func ExecuteActions(action string, tr *desktop.Tracker, mod string, source string) bool {
 	client := tr.ClientWorkspace(tr.ActiveClient())
 	active := tr.ActiveWorkspace()
 
@@ -139,7 +287,7 @@ func ExecuteActions(action string, tr *desktop.Tracker, mod string) bool {
 		}
 
 		// Execute action and store results
-		success := ExecuteAction(action, tr, ws)
+		success := ExecuteAction(action, tr, ws, source)
 		results = append(results, success)
 	}
 
@@ -147,7 +295,9 @@ func ExecuteActions(action string, tr *desktop.Tracker, mod string) bool {
 }
 
 func EnableTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	ws.Unpause()
 	ws.EnableTiling()
+	tr.Unfloat()
 	tr.Update()
 	tr.Tile(ws)
 
@@ -158,11 +308,14 @@ func EnableTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 }
 
 func DisableTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
-	if ws.TilingDisabled() {
+	paused := ws.Unpause()
+	if !paused && ws.TilingDisabled() {
 		return false
 	}
 	ws.DisableTiling()
-	tr.Restore(ws, store.Latest)
+	if !paused {
+		tr.Restore(ws, store.Latest)
+	}
 
 	ui.ShowLayout(ws)
 	ui.UpdateIcon(ws)
@@ -177,6 +330,113 @@ func ToggleTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return DisableTiling(tr, ws)
 }
 
+func EnableTilingAll(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	for _, w := range tr.Workspaces {
+		w.Unpause()
+		w.EnableTiling()
+	}
+	tr.Unfloat()
+	tr.Update()
+
+	// Tile all workspaces
+	for _, w := range tr.Workspaces {
+		tr.Tile(w)
+	}
+	tr.Write()
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func DisableTilingAll(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	for _, w := range tr.Workspaces {
+		w.Unpause()
+		if w.TilingDisabled() {
+			continue
+		}
+		w.DisableTiling()
+		tr.Restore(w, store.Latest)
+	}
+	tr.Write()
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func SetLayoutAll(tr *desktop.Tracker, ws *desktop.Workspace, name string) bool {
+	name = strings.TrimSpace(name)
+
+	// Validate layout name
+	valid := false
+	for _, l := range ws.Layouts {
+		valid = valid || l.GetName() == name
+	}
+	if !valid {
+		log.Warn("Unknown layout \"", name, "\"")
+		return false
+	}
+
+	// Set layout on all unlocked workspaces
+	for _, w := range tr.Workspaces {
+		if w.Locked {
+			continue
+		}
+		for i, l := range w.Layouts {
+			if l.GetName() == name {
+				w.SetLayout(uint(i))
+			}
+		}
+		tr.Tile(w)
+	}
+	tr.Write()
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func ToggleLock(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.Locked {
+		ws.UnLock()
+	} else {
+		ws.Lock()
+	}
+	tr.Update()
+	tr.WriteDelayed()
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func PauseTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.Unpause() {
+		return EnableTiling(tr, ws)
+	}
+	if ws.TilingDisabled() || common.Config.TilingPause <= 0 {
+		return false
+	}
+
+	// Pause tiling and resume after timeout
+	ws.Pause(time.Duration(common.Config.TilingPause)*time.Minute, tr.Do, func() {
+		EnableTiling(tr, ws)
+		ui.UpdateTooltip(ws)
+	})
+	tr.Restore(ws, store.Latest)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+	ui.UpdateTooltip(ws)
+
+	return true
+}
+
 func EnableDecoration(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -217,6 +477,65 @@ func ToggleDecoration(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return DisableDecoration(tr, ws)
 }
 
+func ToggleClientDecoration(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	// Override decoration of active client
+	if c.Decoration(mg.DecorationEnabled()) {
+		c.Toggled = "strip"
+	} else {
+		c.Toggled = "keep"
+	}
+	log.Info("Override window decoration with ", c.Toggled, " [", c.Latest.Class, "]")
+
+	tr.Tile(ws)
+	tr.WriteDelayed()
+
+	return true
+}
+
+func TogglePinClient(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := tr.ActiveClient()
+	if c == nil || store.IsSticky(c.Latest) {
+		return false
+	}
+
+	// Pin or unpin active client to current desktop
+	if tr.Pinned[c.Window.Id] {
+		delete(tr.Pinned, c.Window.Id)
+		log.Info("Unpin window [", c.Latest.Class, "]")
+	} else {
+		tr.Pinned[c.Window.Id] = true
+		log.Info("Pin window [", c.Latest.Class, "]")
+	}
+
+	return true
+}
+
+func CalibrateWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := tr.ActiveClient()
+	if c == nil || !c.Calibrate() {
+		return false
+	}
+
+	tr.Tile(ws)
+
+	return true
+}
+
 func Restore(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -230,6 +549,29 @@ func Restore(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
+func TogglePrimary(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	store.Workplace.PrimaryOnly = !store.Workplace.PrimaryOnly
+
+	log.Info("Restrict tiling to primary screen [", store.Workplace.PrimaryOnly, "]")
+
+	// Restore or tile workspaces on current desktop
+	for _, w := range tr.Workspaces {
+		if w.Location.Desktop != ws.Location.Desktop {
+			continue
+		}
+		if w.TilingDisabled() {
+			tr.Restore(w, store.Latest)
+		} else {
+			tr.Tile(w)
+		}
+		ui.ShowLayout(w)
+	}
+	tr.Update()
+	ui.UpdateIcon(tr.ActiveWorkspace())
+
+	return true
+}
+
 func Reset(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -445,6 +787,93 @@ func PreviousWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
+func DesktopBackAndForth(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	previous, ok := store.Desktops.Previous()
+	if !ok {
+		return false
+	}
+
+	store.CurrentDesktopSet(store.X, previous)
+
+	return true
+}
+
+func SwitchDesktop(tr *desktop.Tracker, desktop uint) bool {
+	if desktop >= store.Workplace.DesktopCount {
+		return false
+	}
+
+	// Switch back to previous desktop
+	if common.Config.DesktopBackAndForth && desktop == store.Workplace.CurrentDesktop {
+		return DesktopBackAndForth(tr, tr.ActiveWorkspace())
+	}
+
+	store.CurrentDesktopSet(store.X, desktop)
+
+	return true
+}
+
+func AddDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	return store.NumberOfDesktopsSet(store.X, store.Workplace.DesktopCount+1)
+}
+
+func RemoveDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if store.Workplace.DesktopCount <= 1 {
+		return false
+	}
+	return store.NumberOfDesktopsSet(store.X, store.Workplace.DesktopCount-1)
+}
+
+func NextOccupiedDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	desktop, ok := tr.OccupiedDesktop(1)
+	if !ok {
+		return false
+	}
+
+	store.CurrentDesktopSet(store.X, desktop)
+
+	return true
+}
+
+func PreviousOccupiedDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	desktop, ok := tr.OccupiedDesktop(-1)
+	if !ok {
+		return false
+	}
+
+	store.CurrentDesktopSet(store.X, desktop)
+
+	return true
+}
+
+func FocusEmptyDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	desktop, ok := tr.EmptyDesktop()
+
+	// Create new desktop if all are occupied
+	if !ok && !store.NumberOfDesktopsSet(store.X, desktop+1) {
+		return false
+	}
+
+	store.CurrentDesktopSet(store.X, desktop)
+
+	return true
+}
+
+func WindowToEmptyDesktop(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+	desktop, ok := tr.EmptyDesktop()
+
+	// Create new desktop if all are occupied
+	if !ok && !store.NumberOfDesktopsSet(store.X, desktop+1) {
+		return false
+	}
+
+	return c.MoveToDesktop(uint32(desktop))
+}
+
 func NextScreen(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	c := tr.ActiveClient()
 	if c == nil {
@@ -518,28 +947,476 @@ func MakeMasterPrevious(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return PreviousWindow(tr, ws)
 }
 
-func IncreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+func SelectSwapTarget(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	// Swap active client with selected target
+	return selectTarget(tr, ws, c, func(target *store.Client) {
+		mg := ws.ActiveLayout().GetManager()
+		managed := func(c *store.Client) bool {
+			return mg.Index(mg.Masters, c) >= 0 || mg.Index(mg.Slaves, c) >= 0
+		}
+		if target == c || !managed(target) || !managed(c) {
+			return
+		}
+		mg.SwapClient(c, target)
+		tr.Tile(ws)
+
+		store.ActiveWindowSet(store.X, c.Window)
+	})
+}
+
+func SwapMaster(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil || !ws.ActiveLayout().SwapMaster(c) {
+		return false
+	}
+	tr.Tile(ws)
+
+	store.ActiveWindowSet(store.X, c.Window)
+
+	return true
+}
+
+func CycleMasters(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.ActiveLayout().CycleClients() {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func ShiftWindowUp(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil || !ws.ActiveLayout().ShiftClient(c, -1) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func ShiftWindowDown(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil || !ws.ActiveLayout().ShiftClient(c, 1) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func PromoteWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil || !ws.ActiveLayout().PromoteClient(c) {
+		return false
+	}
+	tr.Tile(ws)
+	tr.WriteDelayed()
+
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func DemoteWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil || !ws.ActiveLayout().DemoteClient(c) {
+		return false
+	}
+	tr.Tile(ws)
+	tr.WriteDelayed()
+
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func IgnoreWindowClass(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := tr.ActiveClient()
+	if c == nil || !common.IgnoreClass(c.Latest.Class) {
+		return false
+	}
+
+	// Untrack clients with same class
+	for w, tc := range tr.Clients {
+		if common.IsIgnoredClass(tc.Latest.Class) {
+			tr.Trackable[w] = false
+		}
+	}
+	tr.Update()
+
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func UnignoreWindowClass(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	info := store.GetInfo(store.Windows.Active.Id)
+	if !common.UnignoreClass(info.Class) {
+		return false
+	}
+
+	// Re-evaluate untracked windows
+	for w, trackable := range tr.Trackable {
+		if !trackable {
+			delete(tr.Trackable, w)
+		}
+	}
+	tr.Update()
+
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func SelectWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if !tr.SelectClient(tr.ActiveClient()) {
+		return false
+	}
+	ui.ShowSelection(tr, ws)
+
+	return true
+}
+
+func SelectPointerWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	pt := store.PointerUpdate(store.X)
+
+	// Select client under pointer
+	ws = tr.WorkspaceAt(store.Workplace.CurrentDesktop, store.ScreenGet(pt.Position))
+	if !tr.SelectClient(tr.ClientAt(ws, pt.Position)) {
+		return false
+	}
+	ui.ShowSelection(tr, ws)
+
+	return true
+}
+
+func ClearSelection(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if !tr.ClearSelection() {
+		return false
+	}
+	ui.HideOverlay(ws)
+
+	return true
+}
+
+func FloatSelection(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	clients := tr.SelectedClients()
+	if len(clients) == 0 {
+		return false
+	}
+
+	// Float selected clients
+	for _, c := range clients {
+		tr.FloatClient(c)
+	}
+	tr.ClearSelection()
+
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func GroupSelection(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	// Obtain selected clients in layout order
+	clients := []*store.Client{}
+	for _, c := range mg.Clients(store.Stacked) {
+		if tr.Selected[c.Window.Id] {
+			clients = append(clients, c)
+		}
+	}
+	if len(clients) < 2 {
+		return false
+	}
+
+	// Insert selected clients after the first one
+	for i := 1; i < len(clients); i++ {
+		mg.InsertClient(clients[i], clients[i-1], true)
+	}
+	tr.ClearSelection()
+	tr.Tile(ws)
+
+	return true
+}
+
+func SelectionToDesktop(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	clients := tr.SelectedClients()
+	if len(clients) == 0 {
+		return false
+	}
+
+	// Validate desktop index
+	desktop, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || desktop < 0 || uint(desktop) >= store.Workplace.DesktopCount {
+		log.Warn("Invalid desktop index ", value)
+		return false
+	}
+
+	// Move selected clients
+	for _, c := range clients {
+		c.MoveToDesktop(uint32(desktop))
+	}
+	tr.ClearSelection()
+
+	return true
+}
+
+func SelectionToScreen(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	clients := tr.SelectedClients()
+	if len(clients) == 0 {
+		return false
+	}
+
+	// Validate screen index
+	screen, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || screen < 0 || uint(screen) >= store.Workplace.ScreenCount {
+		log.Warn("Invalid screen index ", value)
+		return false
+	}
+
+	// Move selected clients
+	for _, c := range clients {
+		tr.MoveToScreen(c, uint(screen))
+	}
+	tr.ClearSelection()
+
+	return true
+}
+
+func IncreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	for i := 0; i < repeats(value); i++ {
+		ws.ActiveLayout().IncreaseProportion()
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func DecreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	for i := 0; i < repeats(value); i++ {
+		ws.ActiveLayout().DecreaseProportion()
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func IncreaseClientProportion(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+	changed := false
+	for i := 0; i < repeats(value); i++ {
+		changed = ws.ActiveLayout().GetManager().IncreaseClientProportion(c) || changed
+	}
+	if !changed {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func DecreaseClientProportion(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+	changed := false
+	for i := 0; i < repeats(value); i++ {
+		changed = ws.ActiveLayout().GetManager().DecreaseClientProportion(c) || changed
+	}
+	if !changed {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func SetProportion(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Parse percentage or fraction
+	value = strings.TrimSpace(value)
+	proportion, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		log.Warn("Error parsing proportion \"", value, "\": ", err)
+		return false
+	}
+	if strings.HasSuffix(value, "%") {
+		proportion /= 100.0
+	}
+	if proportion <= 0.0 || proportion >= 1.0 {
+		log.Warn("Invalid proportion \"", value, "\"")
+		return false
+	}
+	if !ws.ActiveLayout().SetProportion(proportion) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func TogglePresentation(tr *desktop.Tracker, ws *desktop.Workspace, value string) bool {
+	screen := ws.Location.Screen
+
+	// Reset tiling area on second invocation
+	if _, ok := store.TilingAreaGet(screen); ok {
+		store.TilingAreaSet(screen, nil)
+	} else {
+		_, _, dw, dh := store.DesktopGeometry(screen).Pieces()
+
+		// Parse percentage of width or pixel rectangle (WxH+X+Y)
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			value = "70%"
+		}
+		area := &common.Geometry{}
+		if strings.HasSuffix(value, "%") {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || percent <= 0 || percent > 100 {
+				log.Warn("Invalid presentation area \"", value, "\"")
+				return false
+			}
+			area.Width, area.Height = int(math.Round(float64(dw)*percent/100.0)), dh
+		} else {
+			n, err := fmt.Sscanf(value, "%dx%d+%d+%d", &area.Width, &area.Height, &area.X, &area.Y)
+			if err != nil || n != 4 || area.Width <= 0 || area.Height <= 0 || area.X < 0 || area.Y < 0 || area.X >= dw || area.Y >= dh {
+				log.Warn("Invalid presentation area \"", value, "\"")
+				return false
+			}
+		}
+		store.TilingAreaSet(screen, area)
+	}
+
+	// Tile all workspaces of screen
+	TileScreen(tr, screen)
+	ui.ShowLayout(ws)
+
+	return true
+}
+
+func TileScreen(tr *desktop.Tracker, screen uint) {
+	for _, ws := range tr.Workspaces {
+		if ws.Location.Screen == screen {
+			tr.Tile(ws)
+		}
+	}
+}
+
+func EqualizeProportions(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
 	}
-	ws.ActiveLayout().IncreaseProportion()
+	ws.ActiveLayout().EqualizeProportions()
 	tr.Tile(ws)
 
 	return true
 }
 
-func DecreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+func ResetProportions(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
 	}
-	ws.ActiveLayout().DecreaseProportion()
+	ws.ActiveLayout().ResetProportions()
 	tr.Tile(ws)
 
 	return true
 }
 
+func StateDump(tr *desktop.Tracker) bool {
+
+	// Serialize tracker state
+	data, err := json.MarshalIndent(tr.Dump(), "", "  ")
+	if err != nil {
+		log.Warn("Error serializing state: ", err)
+		return false
+	}
+
+	// Write state file
+	path, err := writeDump("state.json", data)
+	if err != nil {
+		log.Warn("Error writing state file ", path, ": ", err)
+		return false
+	}
+
+	log.Info("State dumped to ", path)
+
+	return true
+}
+
+func AuditDump(tr *desktop.Tracker) bool {
+
+	// Serialize executed actions
+	data, err := json.MarshalIndent(tr.Audit.Latest(0), "", "  ")
+	if err != nil {
+		log.Warn("Error serializing audit log: ", err)
+		return false
+	}
+
+	// Write audit file
+	path, err := writeDump("audit.json", data)
+	if err != nil {
+		log.Warn("Error writing audit file ", path, ": ", err)
+		return false
+	}
+
+	log.Info("Audit log dumped to ", path)
+
+	return true
+}
+
 func Restart(tr *desktop.Tracker) bool {
 	tr.Write()
+	common.FlushCache()
 
 	xevent.Detach(store.X, store.X.RootWin())
 
@@ -555,6 +1432,7 @@ func Restart(tr *desktop.Tracker) bool {
 
 	// Communicate application exit
 	Disconnect()
+	common.NotifySystemd("RELOADING=1")
 
 	// Restart application
 	syscall.Exec(common.Process.Path, os.Args, os.Environ())
@@ -564,6 +1442,7 @@ func Restart(tr *desktop.Tracker) bool {
 
 func Exit(tr *desktop.Tracker) bool {
 	tr.Write()
+	common.FlushCache()
 
 	xevent.Detach(store.X, store.X.RootWin())
 
@@ -579,6 +1458,7 @@ func Exit(tr *desktop.Tracker) bool {
 
 	// Communicate application exit
 	Disconnect()
+	common.NotifySystemd("STOPPING=1")
 
 	// Exit application
 	os.Exit(0)
@@ -586,6 +1466,39 @@ func Exit(tr *desktop.Tracker) bool {
 	return true
 }
 
+func Reconnect(tr *desktop.Tracker) bool {
+	done := make(chan bool)
+
+	// Write cache without blocking on a stuck worker
+	tr.Do(func() {
+		tr.Write()
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(2000 * time.Millisecond):
+		log.Warn("Error writing cache before reconnect")
+	}
+	common.FlushCache()
+
+	// Release callbacks of the lost connection
+	xevent.Detach(store.X, store.X.RootWin())
+
+	log.Info("Reconnect")
+
+	// Communicate application exit
+	Disconnect()
+	common.NotifySystemd("STATUS=Waiting for X server")
+
+	// Wait for X server
+	store.Reconnectable()
+
+	// Restart application
+	syscall.Exec(common.Process.Path, os.Args, os.Environ())
+
+	return true
+}
+
 func External(command string) bool {
 	params := strings.Split(command, " ")
 
@@ -617,3 +1530,41 @@ func executeCallbacks(action string, desktop uint, screen uint) {
 		fun(action, desktop, screen)
 	}
 }
+
+func writeDump(name string, data []byte) (string, error) {
+	path := filepath.Join(common.Args.Cache, name)
+	if common.CacheDisabled() {
+		return name, fmt.Errorf("cache is disabled")
+	}
+
+	// Encrypt dump like other cache files
+	data, err := common.EncryptCache(data)
+	if err != nil {
+		return path, err
+	}
+
+	// Write private temp file (never follows existing files or links)
+	if err := os.MkdirAll(common.Args.Cache, 0755); err != nil {
+		return path, err
+	}
+	file, err := os.CreateTemp(common.Args.Cache, name+".*.tmp")
+	if err != nil {
+		return path, err
+	}
+	_, err = file.Write(data)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return path, err
+	}
+
+	// Replace dump file atomically
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return path, err
+	}
+
+	return path, nil
+}
@@ -43,10 +43,12 @@ func (m Methods) ActionExecute(name string, desktop int32, screen int32) (string
 	success := false
 
 	// Execute action
-	ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
-	if ws != nil {
-		success = ExecuteAction(name, m.Tracker, ws)
-	}
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+		if ws != nil {
+			success = ExecuteAction(name, m.Tracker, ws, "dbus")
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -54,14 +56,33 @@ func (m Methods) ActionExecute(name string, desktop int32, screen int32) (string
 	return dataMap("Result", "ActionExecute", result), nil
 }
 
+func (m Methods) ActionExecuteNamed(name string, workspace string) (string, *dbus.Error) {
+	success := false
+
+	// Execute action
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceNamed(workspace)
+		if ws != nil {
+			success = ExecuteAction(name, m.Tracker, ws, "dbus")
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "ActionExecuteNamed", result), nil
+}
+
 func (m Methods) WindowActivate(id int32) (string, *dbus.Error) {
 	success := false
 
 	// Activate window
-	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok {
-		store.ActiveWindowSet(store.X, c.Window)
-		success = true
-	}
+	m.Tracker.Exec(func() {
+		if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok {
+			store.ActiveWindowSet(store.X, c.Window)
+			success = true
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -73,12 +94,14 @@ func (m Methods) WindowToPosition(id int32, x int32, y int32) (string, *dbus.Err
 	success := false
 
 	// Move window to position
-	valid := x >= 0 && y >= 0
-	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
-		ewmh.MoveWindow(store.X, c.Window.Id, int(x), int(y))
-		store.Pointer.Press()
-		success = true
-	}
+	m.Tracker.Exec(func() {
+		valid := x >= 0 && y >= 0
+		if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
+			ewmh.MoveWindow(store.X, c.Window.Id, int(x), int(y))
+			store.Pointer.Press()
+			success = true
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -90,10 +113,12 @@ func (m Methods) WindowToDesktop(id int32, desktop int32) (string, *dbus.Error)
 	success := false
 
 	// Move window to desktop
-	valid := desktop >= 0 && uint(desktop) < store.Workplace.DesktopCount
-	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
-		success = c.MoveToDesktop(uint32(desktop))
-	}
+	m.Tracker.Exec(func() {
+		valid := desktop >= 0 && uint(desktop) < store.Workplace.DesktopCount
+		if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
+			success = c.MoveToDesktop(uint32(desktop))
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -105,10 +130,12 @@ func (m Methods) WindowToScreen(id int32, screen int32) (string, *dbus.Error) {
 	success := false
 
 	// Move window to screen
-	valid := screen >= 0 && uint(screen) < store.Workplace.ScreenCount
-	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
-		success = c.MoveToScreen(uint32(screen))
-	}
+	m.Tracker.Exec(func() {
+		valid := screen >= 0 && uint(screen) < store.Workplace.ScreenCount
+		if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
+			success = c.MoveToScreen(uint32(screen))
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -116,15 +143,71 @@ func (m Methods) WindowToScreen(id int32, screen int32) (string, *dbus.Error) {
 	return dataMap("Result", "WindowToScreen", result), nil
 }
 
+func (m Methods) WindowToWorkspace(id int32, workspace string) (string, *dbus.Error) {
+	success := false
+
+	// Move window to desktop and screen
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceNamed(workspace)
+		if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && ws != nil {
+			success = c.MoveToDesktop(uint32(ws.Location.Desktop))
+			if c.Latest.Location.Screen != ws.Location.Screen {
+				success = c.MoveToScreen(uint32(ws.Location.Screen)) && success
+			}
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "WindowToWorkspace", result), nil
+}
+
+func (m Methods) WorkspaceRename(desktop int32, screen int32, name string) (string, *dbus.Error) {
+	success := false
+
+	// Rename workspace
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+		if ws != nil {
+			m.Tracker.RenameWorkspace(ws, name)
+			success = true
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "WorkspaceRename", result), nil
+}
+
+func (m Methods) WorkspaceSwitch(workspace string) (string, *dbus.Error) {
+	success := false
+
+	// Switch current desktop
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceNamed(workspace)
+		if ws != nil {
+			success = SwitchDesktop(m.Tracker, ws.Location.Desktop)
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "WorkspaceSwitch", result), nil
+}
+
 func (m Methods) DesktopSwitch(desktop int32) (string, *dbus.Error) {
 	success := false
 
 	// Switch current desktop
-	valid := desktop >= 0 && uint(desktop) < store.Workplace.DesktopCount
-	if valid {
-		store.CurrentDesktopSet(store.X, uint(desktop))
-		success = true
-	}
+	m.Tracker.Exec(func() {
+		valid := desktop >= 0 && uint(desktop) < store.Workplace.DesktopCount
+		if valid {
+			success = SwitchDesktop(m.Tracker, uint(desktop))
+		}
+	})
 
 	// Return result
 	result := common.Map{"Success": success}
@@ -132,6 +215,281 @@ func (m Methods) DesktopSwitch(desktop int32) (string, *dbus.Error) {
 	return dataMap("Result", "DesktopSwitch", result), nil
 }
 
+func (m Methods) ProportionSet(proportion float64, desktop int32, screen int32) (string, *dbus.Error) {
+	success := false
+
+	// Set master-slave proportion
+	m.Tracker.Exec(func() {
+		ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+		if ws != nil {
+			success = SetProportion(m.Tracker, ws, strconv.FormatFloat(proportion, 'f', -1, 64))
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "ProportionSet", result), nil
+}
+
+func (m Methods) ReserveAdd(screen int32, edge string, pixels int32) (string, *dbus.Error) {
+	success := false
+
+	// Reserve screen region
+	m.Tracker.Exec(func() {
+		if screen >= 0 && pixels >= 0 {
+			success = store.ReserveAdd(uint(screen), edge, int(pixels))
+		}
+		if success {
+			TileScreen(m.Tracker, uint(screen))
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "ReserveAdd", result), nil
+}
+
+func (m Methods) ReserveRemove(screen int32, edge string) (string, *dbus.Error) {
+	success := false
+
+	// Remove reserved screen region
+	m.Tracker.Exec(func() {
+		if edge == "all" {
+			edge = ""
+		}
+		if screen >= 0 {
+			success = store.ReserveRemove(uint(screen), edge)
+		}
+		if success {
+			TileScreen(m.Tracker, uint(screen))
+		}
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "ReserveRemove", result), nil
+}
+
+func (m Methods) ReserveList() (string, *dbus.Error) {
+	var result common.Map
+
+	// List reserved screen regions
+	m.Tracker.Exec(func() {
+		result = common.Map{
+			"Display": store.Workplace.Displays.Name,
+			"Regions": store.ReservationsGet(),
+		}
+	})
+
+	// Return result
+	return dataMap("Result", "ReserveList", result), nil
+}
+
+func (m Methods) RulesTest(id int32) (string, *dbus.Error) {
+	var result common.Map
+
+	// Evaluate window rules
+	m.Tracker.Exec(func() {
+		w := xproto.Window(id)
+		if w == 0 {
+			w = store.Windows.Active.Id
+		}
+		info := store.GetInfo(w)
+		result = common.Map{
+			"Window":  w,
+			"Class":   info.Class,
+			"Name":    info.Name,
+			"Process": info.Process,
+			"Types":   info.Types,
+			"States":  info.States,
+			"Rules":   store.Rules(w, info),
+			"Errors":  store.PatternErrors(),
+			"Tracked": m.Tracker.Clients[w] != nil,
+		}
+	})
+
+	// Return result
+	return dataMap("Result", "RulesTest", result), nil
+}
+
+func (m Methods) WindowInspect(id int32) (string, *dbus.Error) {
+	var result common.Map
+
+	// Inspect window
+	m.Tracker.Exec(func() {
+		w := xproto.Window(id)
+		if w == 0 {
+			w = store.Windows.Active.Id
+		}
+		result = common.Map{
+			"Window":    w,
+			"Info":      store.GetInfo(w),
+			"Tracked":   false,
+			"Trackable": m.Tracker.Trackable[w],
+		}
+
+		// Add tracking details
+		c, ok := m.Tracker.Clients[w]
+		if !ok {
+			return
+		}
+		x, y, width, height := c.OuterGeometry()
+		result["Tracked"] = true
+		result["Geometry"] = common.Geometry{X: x, Y: y, Width: width, Height: height}
+		if ws := m.Tracker.ClientWorkspace(c); ws != nil {
+			mg := ws.ActiveLayout().GetManager()
+			slot := common.Map{"Area": "slave", "Index": mg.Index(mg.Slaves, c)}
+			if mg.IsMaster(c) {
+				slot = common.Map{"Area": "master", "Index": mg.Index(mg.Masters, c)}
+			}
+			result["Workspace"] = ws.Name
+			result["Layout"] = ws.ActiveLayout().GetName()
+			result["Slot"] = slot
+		}
+	})
+
+	// Return result
+	return dataMap("Result", "WindowInspect", result), nil
+}
+
+func (m Methods) FocusHistory(count int32) (string, *dbus.Error) {
+	history := []common.Map{}
+
+	// Obtain focus history of workspaces
+	m.Tracker.Exec(func() {
+		for _, ws := range m.Tracker.Workspaces {
+			for _, f := range m.Tracker.FocusHistory(ws) {
+				c := m.Tracker.Clients[f.Window]
+				history = append(history, common.Map{
+					"Window":    f.Window,
+					"Class":     c.Latest.Class,
+					"Name":      c.Latest.Name,
+					"Workspace": ws.Name,
+					"Time":      f.Time,
+				})
+			}
+		}
+	})
+
+	// Sort by focus time (newest first)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i]["Time"].(int64) > history[j]["Time"].(int64)
+	})
+	if count > 0 && len(history) > int(count) {
+		history = history[:count]
+	}
+
+	// Return result
+	result := common.Map{"History": history}
+
+	return dataMap("Result", "FocusHistory", result), nil
+}
+
+func (m Methods) AuditLog(count int32) (string, *dbus.Error) {
+	var entries []desktop.AuditEntry
+
+	// Obtain recently executed actions
+	m.Tracker.Exec(func() {
+		entries = m.Tracker.Audit.Latest(int(count))
+	})
+
+	// Return result
+	result := common.Map{"Entries": entries}
+
+	return dataMap("Result", "AuditLog", result), nil
+}
+
+func (m Methods) KeyAdd(action string, key string, persist int32) (string, *dbus.Error) {
+	success := false
+
+	// Add key binding
+	m.Tracker.Exec(func() {
+		success = AddKey(m.Tracker, action, key, persist != 0)
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "KeyAdd", result), nil
+}
+
+func (m Methods) KeyRemove(action string, persist int32) (string, *dbus.Error) {
+	success := false
+
+	// Remove key binding
+	m.Tracker.Exec(func() {
+		success = RemoveKey(m.Tracker, action, persist != 0)
+	})
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "KeyRemove", result), nil
+}
+
+func (m Methods) KeyList() (string, *dbus.Error) {
+	bindings := []common.Map{}
+
+	// Obtain active key bindings
+	m.Tracker.Exec(func() {
+		for _, b := range KeyBindings() {
+			bindings = append(bindings, common.Map{
+				"Action":  b.Action,
+				"Mod":     b.Mod,
+				"Key":     b.Key,
+				"Grabbed": grabbed[b.Key],
+			})
+		}
+	})
+
+	// Return result
+	result := common.Map{"Bindings": bindings}
+
+	return dataMap("Result", "KeyList", result), nil
+}
+
+func (m Methods) KeysDoctor() (string, *dbus.Error) {
+	var result common.Map
+
+	// Check key bindings for conflicts
+	m.Tracker.Exec(func() {
+		result = common.Map{
+			"Bindings":  len(KeyBindings()),
+			"Conflicts": KeyConflicts(),
+		}
+	})
+
+	// Return result
+	return dataMap("Result", "KeysDoctor", result), nil
+}
+
+func (m Methods) Statistics() (string, *dbus.Error) {
+	var result common.Map
+
+	// Serialize usage statistics
+	m.Tracker.Exec(func() {
+		result = structToMap(m.Tracker.Statistics())
+	})
+
+	// Return result
+	return dataMap("Result", "Statistics", result), nil
+}
+
+func (m Methods) StateDump() (string, *dbus.Error) {
+	var result common.Map
+
+	// Serialize tracker state
+	m.Tracker.Exec(func() {
+		result = structToMap(m.Tracker.Dump())
+	})
+
+	// Return result
+	return dataMap("Result", "StateDump", result), nil
+}
+
 func (m Methods) Introspection() []introspect.Method {
 	typ := reflect.TypeOf(m)
 	ims := make([]introspect.Method, 0, typ.NumMethod())
@@ -193,6 +551,11 @@ func BindDbus(tr *desktop.Tracker) {
 		})
 	})
 
+	// Attach events metrics
+	store.OnEventsUpdate(func(events store.XEvents) {
+		SetProperty("Events", events)
+	})
+
 	// Attach pointer events
 	store.OnPointerUpdate(func(pointer store.XPointer, desktop uint, screen uint) {
 		SetProperty("Pointer", struct {
@@ -209,9 +572,9 @@ func event(ch chan string, tr *desktop.Tracker) {
 	for {
 		switch <-ch {
 		case "clients_change":
-			SetProperty("Clients", common.Map{"Values": maps.Values(tr.Clients)})
+			tr.Exec(func() { SetProperty("Clients", common.Map{"Values": maps.Values(tr.Clients)}) })
 		case "workspaces_change":
-			SetProperty("Workspaces", common.Map{"Values": maps.Values(tr.Workspaces)})
+			tr.Exec(func() { SetProperty("Workspaces", common.Map{"Values": maps.Values(tr.Workspaces)}) })
 		case "workplace_change":
 			SetProperty("Workplace", *store.Workplace)
 		case "windows_change":
@@ -272,6 +635,7 @@ func export(tr *desktop.Tracker) {
 		"Windows":       common.Map{},
 		"Clients":       common.Map{},
 		"Pointer":       common.Map{},
+		"Events":        common.Map{},
 		"Action":        common.Map{},
 		"Corner":        common.Map{},
 		"Disconnect":    common.Map{},
@@ -290,15 +654,36 @@ func export(tr *desktop.Tracker) {
 		return
 	}
 
+	// Replay events emitted before export
+	tr.Exec(func() { tr.Replay(tr.Channels.Event) })
+
 	// Export dbus methods
 	methods = &Methods{
 		Naming: map[string][]string{
-			"ActionExecute":    {"name", "desktop", "screen"},
-			"WindowActivate":   {"id"},
-			"WindowToPosition": {"id", "x", "y"},
-			"WindowToDesktop":  {"id", "desktop"},
-			"WindowToScreen":   {"id", "screen"},
-			"DesktopSwitch":    {"desktop"},
+			"ActionExecute":      {"name", "desktop", "screen"},
+			"ActionExecuteNamed": {"name", "workspace"},
+			"WindowActivate":     {"id"},
+			"WindowToPosition":   {"id", "x", "y"},
+			"WindowToDesktop":    {"id", "desktop"},
+			"WindowToScreen":     {"id", "screen"},
+			"WindowToWorkspace":  {"id", "workspace"},
+			"WorkspaceRename":    {"desktop", "screen", "name"},
+			"WorkspaceSwitch":    {"workspace"},
+			"DesktopSwitch":      {"desktop"},
+			"ProportionSet":      {"proportion", "desktop", "screen"},
+			"ReserveAdd":         {"screen", "edge", "pixels"},
+			"ReserveRemove":      {"screen", "edge"},
+			"ReserveList":        {},
+			"RulesTest":          {"id"},
+			"WindowInspect":      {"id"},
+			"FocusHistory":       {"count"},
+			"AuditLog":           {"count"},
+			"KeyAdd":             {"action", "key", "persist"},
+			"KeyRemove":          {"action", "persist"},
+			"KeyList":            {},
+			"KeysDoctor":         {},
+			"Statistics":         {},
+			"StateDump":          {},
 		},
 		Tracker: tr,
 	}
@@ -383,6 +768,10 @@ func Introspect() map[string][]string {
 }
 
 func Method(name string, args []string) {
+	fmt.Println(callMethod(name, args))
+}
+
+func callMethod(name string, args []string) string {
 	conn, err := connect()
 	if err != nil {
 		fatal("Error initializing dbus server", err)
@@ -406,13 +795,18 @@ func Method(name string, args []string) {
 		fatal("Error calling dbus method", call.Err)
 	}
 
-	// Print reply
+	// Return reply
 	var reply string
 	call.Store(&reply)
-	fmt.Println(reply)
+
+	return reply
 }
 
 func Property(name string) {
+	print("Property", name, readProperty(name))
+}
+
+func readProperty(name string) common.Map {
 	conn, err := connect()
 	if err != nil {
 		fatal("Error initializing dbus server", err)
@@ -428,10 +822,11 @@ func Property(name string) {
 		fatal("Error receiving dbus property", call.Err)
 	}
 
-	// Print reply
+	// Return reply
 	var reply dbus.Variant
 	call.Store(&reply)
-	print("Property", name, variantToMap(reply))
+
+	return variantToMap(reply)
 }
 
 func Listen(args []string) {
@@ -478,6 +873,23 @@ func Listen(args []string) {
 	}
 }
 
+func Notify(summary string, body string) {
+	go func() {
+		conn, err := dbus.SessionBus()
+		if err != nil {
+			log.Warn("Error initializing notification: ", err)
+			return
+		}
+
+		// Show desktop notification
+		obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+		call := obj.Call("org.freedesktop.Notifications.Notify", 0, common.Build.Name, uint32(0), "", summary, body, []string{}, map[string]dbus.Variant{}, int32(-1))
+		if call.Err != nil {
+			log.Warn("Error showing notification: ", call.Err)
+		}
+	}()
+}
+
 func Disconnect() {
 	SetProperty("Disconnect", struct {
 		Event string
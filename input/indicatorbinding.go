@@ -0,0 +1,20 @@
+package input
+
+import (
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/ui"
+)
+
+func BindIndicator(tr *desktop.Tracker) {
+
+	// Update indicators on workspace changes (indicator may be enabled on config reload)
+	_, ch := tr.Register()
+	go func() {
+		for event := range ch {
+			switch event {
+			case "workspaces_change", "workplace_change":
+				tr.Exec(func() { ui.UpdateIndicators(tr) })
+			}
+		}
+	}()
+}
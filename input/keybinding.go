@@ -1,22 +1,147 @@
 package input
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
 
 	"github.com/jezek/xgbutil"
 	"github.com/jezek/xgbutil/keybind"
+	"github.com/jezek/xgbutil/mousebind"
 	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xwindow"
 
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
 	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
 
 	log "github.com/sirupsen/logrus"
 )
 
+type KeyBinding struct {
+	Action string // Action of key binding
+	Mod    string // Modifier scope of key binding (current | screens | workspaces)
+	Key    string // Key combination of key binding
+}
+
+type KeyConflict struct {
+	Action string // Action of conflicting key binding
+	Key    string // Key combination of conflicting key binding
+	Reason string // Reason of failed key grab
+}
+
+var (
+	active    = map[string][]KeyBinding{} // Active key bindings per key combination
+	connected = map[string]bool{}         // Key combinations with attached callbacks
+	grabbed   = map[string]bool{}         // Key combinations grabbed by this instance
+	reported  = ""                        // Key combinations of last conflict report
+)
+
 func BindKeys(tr *desktop.Tracker) {
 	keybind.Initialize(store.X)
 
+	// Bind keyboard shortcuts
+	reportConflicts(SyncKeys(tr))
+
+	// Rebind keyboard shortcuts on config reload
+	common.OnConfigUpdate(func() {
+		tr.Do(func() { reportConflicts(SyncKeys(tr)) })
+	})
+
+	// Bind action channel
+	go action(tr.Channels.Action, tr)
+}
+
+func SyncKeys(tr *desktop.Tracker) []KeyConflict {
+	result := []KeyConflict{}
+
+	// Group key bindings by key combination
+	bindings := map[string][]KeyBinding{}
+	for _, b := range KeyBindings() {
+		bindings[b.Key] = append(bindings[b.Key], b)
+	}
+
+	// Release key combinations that are no longer bound
+	for key := range grabbed {
+		if _, ok := bindings[key]; !ok {
+			ungrab(key)
+		}
+	}
+
+	// Grab key combinations that are newly bound
+	for key, b := range bindings {
+		if grabbed[key] {
+			continue
+		}
+		if err := grab(key, tr); err != nil {
+			log.Warn("Error on action ", b[0].Action, ": ", err)
+			result = append(result, KeyConflict{Action: b[0].Action, Key: key, Reason: conflictReason(err)})
+		}
+	}
+	active = bindings
+
+	return result
+}
+
+func AddKey(tr *desktop.Tracker, action string, key string, persist bool) bool {
+	if len(action) == 0 || len(key) == 0 {
+		return false
+	}
+
+	// Bind key combination at runtime
+	previous, ok := common.Config.Keys[action]
+	common.Config.Keys[action] = key
+	SyncKeys(tr)
+
+	// Revert key binding that could not be grabbed
+	if !grabbed[normalizeKey(key)] && !strings.HasPrefix(action, "mod_") {
+		if ok {
+			common.Config.Keys[action] = previous
+		} else {
+			delete(common.Config.Keys, action)
+		}
+		SyncKeys(tr)
+		return false
+	}
+
+	log.Info("Add key binding ", action, " = \"", key, "\"")
+
+	// Persist key binding
+	if persist {
+		return common.PersistKey(action, key)
+	}
+
+	return true
+}
+
+func RemoveKey(tr *desktop.Tracker, action string, persist bool) bool {
+	if len(common.Config.Keys[action]) == 0 {
+		return false
+	}
+
+	// Unbind key combination at runtime
+	common.Config.Keys[action] = ""
+	SyncKeys(tr)
+
+	log.Info("Remove key binding ", action)
+
+	// Persist key binding
+	if persist {
+		return common.PersistKey(action, "")
+	}
+
+	return true
+}
+
+func KeyBindings() []KeyBinding {
+	bindings := []KeyBinding{}
 	actions := map[string]string{}
 	mods := map[string]string{"current": ""}
 
@@ -32,33 +157,351 @@ func BindKeys(tr *desktop.Tracker) {
 		}
 	}
 
-	// Bind keyboard shortcuts
+	// Combine actions with modifiers
 	for a, ak := range actions {
 		for m, mk := range mods {
 			if len(mk) == 0 {
-				bind(ak, a, m, tr)
+				bindings = append(bindings, KeyBinding{Action: a, Mod: m, Key: normalizeKey(ak)})
 			} else {
-				bind(mk+"-"+ak, a, m, tr)
+				bindings = append(bindings, KeyBinding{Action: a, Mod: m, Key: normalizeKey(mk + "-" + ak)})
 			}
 		}
 	}
 
-	// Bind action channel
-	go action(tr.Channels.Action, tr)
+	// Sort by key combination
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].Key < bindings[j].Key || (bindings[i].Key == bindings[j].Key && bindings[i].Action < bindings[j].Action)
+	})
+
+	return bindings
+}
+
+func KeyConflicts() []KeyConflict {
+	result := []KeyConflict{}
+	actions := map[string]string{}
+
+	for _, b := range KeyBindings() {
+
+		// Check key combinations used by multiple actions
+		if other, ok := actions[b.Key]; ok {
+			result = append(result, KeyConflict{Action: b.Action, Key: b.Key, Reason: "also bound to action " + other})
+			continue
+		}
+		actions[b.Key] = b.Action
+
+		// Check key combinations owned by other clients
+		if err := probe(b.Key); err != nil {
+			result = append(result, KeyConflict{Action: b.Action, Key: b.Key, Reason: err.Error()})
+		}
+	}
+
+	return result
+}
+
+func grab(key string, tr *desktop.Tracker) error {
+	if connected[key] {
+		return regrab(key)
+	}
+
+	// Execute actions bound to key combination
+	execute := func() {
+		tr.Do(func() {
+			for _, b := range active[key] {
+				schedule(tr, b.Action, b.Mod, "keybinding")
+			}
+		})
+	}
+
+	// Attach callback on first grab
+	var err error
+	if button, ok := buttonString(key); ok {
+		err = mousebind.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+			execute()
+		}).Connect(store.X, store.X.RootWin(), button, false, true)
+	} else {
+		err = keybind.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+			execute()
+		}).Connect(store.X, store.X.RootWin(), key, true)
+	}
+	if err != nil {
+		return err
+	}
+	connected[key] = true
+	grabbed[key] = true
+
+	return nil
+}
+
+func regrab(key string) error {
+
+	// Grab button combination of attached callback again
+	if button, ok := buttonString(key); ok {
+		mods, button, err := mousebind.ParseString(store.X, button)
+		if err != nil {
+			return err
+		}
+		if err := mousebind.GrabChecked(store.X, store.X.RootWin(), mods, button, false); err != nil {
+			mousebind.Ungrab(store.X, store.X.RootWin(), mods, button)
+			return err
+		}
+		grabbed[key] = true
+		return nil
+	}
+
+	// Grab key combination of attached callback again
+	mods, keycodes, err := keybind.ParseString(store.X, key)
+	if err != nil {
+		return err
+	}
+	for _, keycode := range keycodes {
+		if err := keybind.GrabChecked(store.X, store.X.RootWin(), mods, keycode); err != nil {
+			for _, keycode := range keycodes {
+				keybind.Ungrab(store.X, store.X.RootWin(), mods, keycode)
+			}
+			return err
+		}
+	}
+	grabbed[key] = true
+
+	return nil
+}
+
+func ungrab(key string) {
+	delete(grabbed, key)
+
+	// Release button combination, the attached callback stays inactive
+	if button, ok := buttonString(key); ok {
+		mods, button, err := mousebind.ParseString(store.X, button)
+		if err == nil {
+			mousebind.Ungrab(store.X, store.X.RootWin(), mods, button)
+		}
+		return
+	}
+
+	// Release key combination, the attached callback stays inactive
+	mods, keycodes, err := keybind.ParseString(store.X, key)
+	if err != nil {
+		return
+	}
+	for _, keycode := range keycodes {
+		keybind.Ungrab(store.X, store.X.RootWin(), mods, keycode)
+	}
 }
 
-func bind(key string, action string, mod string, tr *desktop.Tracker) {
-	err := keybind.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
-		ExecuteActions(action, tr, mod)
-	}).Connect(store.X, store.X.RootWin(), key, true)
+func probe(key string) error {
+	if grabbed[key] {
+		return nil
+	}
 
+	// Grab and release key or button combination
+	err := regrab(key)
+	ungrab(key)
 	if err != nil {
-		log.Warn("Error on action ", action, ": ", err)
+		return errors.New(conflictReason(err))
+	}
+
+	return nil
+}
+
+func normalizeKey(key string) string {
+	parts := strings.Split(key, "-")
+	last := len(parts) - 1
+	name := strings.ToLower(parts[last])
+
+	// Resolve raw keysyms through keysym definitions
+	if sym, err := strconv.ParseUint(name, 0, 32); err == nil && strings.HasPrefix(name, "0x") {
+		if str := keybind.KeysymToStr(xproto.Keysym(sym)); len(str) > 0 {
+			parts[last] = str
+		}
+	} else if strings.HasPrefix(name, "button") {
+		parts[last] = "Button" + name[6:]
+	}
+
+	return strings.Join(parts, "-")
+}
+
+func buttonString(key string) (string, bool) {
+	parts := strings.Split(key, "-")
+	last := len(parts) - 1
+
+	// Map primary buttons combined with modifiers
+	switch parts[last] {
+	case "Button1", "Button2", "Button3":
+		if last == 0 {
+			return key, false
+		}
+		parts[last] = strings.TrimPrefix(parts[last], "Button")
+
+	// Map side buttons of pointer devices
+	case "Button8":
+		parts[last] = "8"
+	case "Button9":
+		parts[last] = "9"
+	default:
+		return key, false
+	}
+
+	return strings.Join(parts, "-"), true
+}
+
+func conflictReason(err error) string {
+	if _, ok := err.(xproto.AccessError); ok || strings.Contains(err.Error(), "bad access") {
+		return "already grabbed by the window manager or another client"
+	}
+	return err.Error()
+}
+
+func reportConflicts(result []KeyConflict) {
+	keys := []string{}
+	for _, c := range result {
+		keys = append(keys, c.Key+" ("+c.Action+")")
+	}
+
+	// Skip unchanged reports
+	report := strings.Join(keys, ", ")
+	if report == reported {
+		return
+	}
+	reported = report
+
+	if len(result) == 0 {
+		return
 	}
+
+	// Report conflicting key combinations
+	for _, c := range result {
+		log.Warn("Key conflict ", c.Key, " on action ", c.Action, ": ", c.Reason)
+	}
+	Notify("Key conflicts", fmt.Sprintf("%d key bindings are unavailable: %s", len(result), report))
 }
 
 func action(ch chan string, tr *desktop.Tracker) {
 	for {
-		ExecuteAction(<-ch, tr, tr.ActiveWorkspace())
+		action := <-ch
+		tr.Do(func() { ExecuteAction(action, tr, tr.ActiveWorkspace(), "internal") })
+	}
+}
+
+func selectTarget(tr *desktop.Tracker, ws *desktop.Workspace, active *store.Client, fun func(*store.Client)) bool {
+	clients := []*store.Client{}
+	for _, c := range ws.VisibleClients() {
+		if c != nil {
+			clients = append(clients, c)
+		}
+	}
+	if len(clients) < 2 {
+		return false
+	}
+
+	// Skip keyboard grab without visible target
+	if common.Config.TilingGui <= 0 {
+		log.Warn("Error selecting target: tiling_gui is disabled")
+		return false
+	}
+
+	// Preselect neighbor of active client
+	target := neighbor(active, clients, 1, 0)
+	if target == nil {
+		target = neighbor(active, clients, -1, 0)
+	}
+	if target == nil {
+		target = clients[0]
+	}
+
+	// Create input window to receive keyboard events
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		return false
+	}
+	err = win.CreateChecked(store.X.RootWin(), -1, -1, 1, 1, xproto.CwOverrideRedirect, 1)
+	if err != nil {
+		return false
+	}
+	win.Map()
+	if err := keybind.GrabKeyboard(store.X, win.Id); err != nil {
+		log.Warn("Error grabbing keyboard: ", err)
+		win.Destroy()
+		return false
+	}
+
+	// Release keyboard on selection end
+	done := false
+	finish := func(selected *store.Client) {
+		if done {
+			return
+		}
+		done = true
+		keybind.Detach(store.X, win.Id)
+		keybind.UngrabKeyboard(store.X)
+		win.Destroy()
+		ui.HideOverlay(ws)
+		if selected != nil {
+			fun(selected)
+		}
+	}
+	if common.Config.WindowSwapTimeout > 0 {
+		time.AfterFunc(time.Duration(common.Config.WindowSwapTimeout)*time.Millisecond, func() {
+			tr.Do(func() { finish(nil) })
+		})
+	}
+
+	// Bind selection keys
+	move := func(dx int, dy int) {
+		if n := neighbor(target, clients, dx, dy); n != nil {
+			target = n
+		}
+	}
+	keys := map[string]func(){
+		"Left":     func() { move(-1, 0) },
+		"Right":    func() { move(1, 0) },
+		"Up":       func() { move(0, -1) },
+		"Down":     func() { move(0, 1) },
+		"Return":   func() { finish(target) },
+		"KP_Enter": func() { finish(target) },
+		"Escape":   func() { finish(nil) },
+	}
+	for key, f := range keys {
+		f := f
+		keybind.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+			tr.Do(func() {
+				if done {
+					return
+				}
+				f()
+				if !done {
+					ui.ShowTarget(ws, target)
+				}
+			})
+		}).Connect(store.X, win.Id, key, false)
+	}
+	ui.ShowTarget(ws, target)
+
+	return true
+}
+
+func neighbor(c *store.Client, clients []*store.Client, dx int, dy int) *store.Client {
+	x, y, w, h := c.OuterGeometry()
+	center := common.Point{X: x + w/2, Y: y + h/2}
+
+	// Find nearest client center in direction
+	var nearest *store.Client
+	distance := math.MaxInt
+	for _, n := range clients {
+		if n == c {
+			continue
+		}
+		nx, ny, nw, nh := n.OuterGeometry()
+		px, py := nx+nw/2-center.X, ny+nh/2-center.Y
+
+		// Weight distance along direction lower than across
+		primary, secondary := px*dx+py*dy, common.AbsInt(px*dy)+common.AbsInt(py*dx)
+		if primary <= 0 {
+			continue
+		}
+		if d := primary + 2*secondary; d < distance {
+			nearest, distance = n, d
+		}
 	}
+
+	return nearest
 }
@@ -0,0 +1,84 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func Launcher(command string, entry string) {
+	entry = strings.TrimSpace(entry)
+
+	// Choose menu command
+	switch command {
+	case "windows":
+		if len(entry) == 0 {
+			menuWindows()
+		} else {
+			menuActivate(entry)
+		}
+	case "actions":
+		if len(entry) == 0 {
+			menuActions()
+		} else {
+			menuExecute(entry)
+		}
+	}
+}
+
+func menuWindows() {
+	clients, _ := readProperty("Clients")["Values"].([]interface{})
+
+	// Print window entries (id class - name)
+	for _, value := range clients {
+		client, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		window, _ := client["Window"].(map[string]interface{})
+		latest, _ := client["Latest"].(map[string]interface{})
+		id, _ := window["Id"].(float64)
+		class, _ := latest["Class"].(string)
+		name, _ := latest["Name"].(string)
+		fmt.Printf("%d %s - %s\n", int64(id), class, name)
+	}
+}
+
+func menuActivate(entry string) {
+	id, _, _ := strings.Cut(entry, " ")
+	if _, err := strconv.Atoi(id); err != nil {
+		return
+	}
+
+	// Activate picked window
+	callMethod("WindowActivate", []string{id})
+}
+
+func menuActions() {
+	config := readProperty("Configuration")
+	keys, _ := config["Keys"].(map[string]interface{})
+
+	// Obtain configured actions
+	actions := []string{}
+	for action := range keys {
+		if !strings.HasPrefix(action, "mod_") {
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+
+	// Print action entries
+	for _, action := range actions {
+		fmt.Println(action)
+	}
+}
+
+func menuExecute(entry string) {
+	workplace := readProperty("Workplace")
+	desktop, _ := workplace["CurrentDesktop"].(float64)
+	screen, _ := workplace["CurrentScreen"].(float64)
+
+	// Execute picked action on active workspace
+	callMethod("ActionExecute", []string{entry, fmt.Sprint(int(desktop)), fmt.Sprint(int(screen))})
+}
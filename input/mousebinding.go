@@ -1,6 +1,7 @@
 package input
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/leukipp/cortile/v2/common"
@@ -15,26 +16,42 @@ var (
 	workspace *desktop.Workspace // Stores previous workspace (for comparison only)
 	pointer   *store.XPointer    // Stores previous pointer (for comparison only)
 	hover     *time.Timer        // Timer to delay hover events
+	drop      string             // Stores previous drop target (for comparison only)
 )
 
 func BindMouse(tr *desktop.Tracker) {
-	poll(100, func() {
-		store.PointerUpdate(store.X)
+	poll(interval, func() {
+		tr.Exec(func() {
 
-		// Reset tracker handler
-		resetTracker(tr)
+			// Skip pointer updates while idle
+			if store.IdleUpdate(store.X).Active {
+				return
+			}
+			store.PointerUpdate(store.X)
 
-		// Evaluate workspace state
-		updateWorkspace(tr)
+			// Reset tracker handler
+			resetTracker(tr)
 
-		// Evaluate corner state
-		updateCorner(tr)
+			// Evaluate workspace state
+			updateWorkspace(tr)
 
-		// Evaluate focus state
-		updateFocus(tr)
+			// Evaluate corner state
+			updateCorner(tr)
 
-		// Store last pointer
-		pointer = store.Pointer
+			// Evaluate focus state
+			updateFocus(tr)
+
+			// Evaluate drop state
+			updateDrop(tr)
+
+			// Store last pointer
+			pointer = store.Pointer
+		})
+	})
+
+	// Attach snap events
+	store.OnSnapUpdate(func(proportion float64, desktop uint, screen uint) {
+		ui.ShowProportion(tr.WorkspaceAt(desktop, screen), proportion)
 	})
 }
 
@@ -49,6 +66,25 @@ func resetTracker(tr *desktop.Tracker) {
 	}
 }
 
+func updateDrop(tr *desktop.Tracker) {
+	target, side := tr.DropTarget()
+
+	// Compare with previous drop target
+	current := ""
+	if target != nil {
+		current = fmt.Sprint(target.Window.Id, side)
+	}
+	if current == drop {
+		return
+	}
+	drop = current
+
+	// Preview drop target
+	if target != nil {
+		ui.ShowDrop(tr.ClientWorkspace(target), target, side)
+	}
+}
+
 func updateWorkspace(tr *desktop.Tracker) {
 	ws := tr.ActiveWorkspace()
 	if ws == nil || ws == workspace {
@@ -57,7 +93,7 @@ func updateWorkspace(tr *desktop.Tracker) {
 	log.Info("Active workspace updated [", ws.Name, "]")
 
 	// Communicate workplace change
-	tr.Channels.Event <- "workplace_change"
+	tr.Emit("workplace_change")
 
 	// Update systray icon
 	ui.UpdateIcon(ws)
@@ -67,21 +103,24 @@ func updateWorkspace(tr *desktop.Tracker) {
 }
 
 func updateCorner(tr *desktop.Tracker) {
+	if !store.Capable("pointer_tracking") {
+		return
+	}
 	hc := store.HotCorner()
 	if hc == nil {
 		return
 	}
 
 	// Communicate corner change
-	tr.Channels.Event <- "corner_change"
+	tr.Emit("corner_change")
 
 	// Execute action
-	ExecuteAction(common.Config.Corners[hc.Name], tr, tr.ActiveWorkspace())
+	ExecuteAction(common.Config.Corners[hc.Name], tr, tr.ActiveWorkspace(), "corner")
 }
 
 func updateFocus(tr *desktop.Tracker) {
 	ws := tr.ActiveWorkspace()
-	if ws == nil || pointer == nil || hover != nil {
+	if ws == nil || pointer == nil || hover != nil || !store.Capable("pointer_tracking") {
 		return
 	}
 
@@ -99,27 +138,39 @@ func updateFocus(tr *desktop.Tracker) {
 	log.Info("Hovered window updated [", hovered.Latest.Class, "]")
 
 	// Delay hover event by given duration
-	if common.Config.WindowFocusDelay == 0 {
+	if common.Config.WindowFocusDelay == 0 || !common.Power().Profile.HoverFocus {
 		return
 	}
 	hover = time.AfterFunc(time.Duration(common.Config.WindowFocusDelay)*time.Millisecond, func() {
-		hover = nil
+		tr.Do(func() { focusHovered(tr, ws, active, hovered) })
+	})
+}
 
-		// Hovered client window has changed in the meantime
-		if hovered != tr.ClientAt(ws, store.Pointer.Position) {
-			return
-		}
+func focusHovered(tr *desktop.Tracker, ws *desktop.Workspace, active *store.Client, hovered *store.Client) {
+	hover = nil
 
-		// Focus hovered client window
-		if hovered != active && ws.TilingEnabled() && !tr.Handlers.Active() {
-			store.ActiveWindowSet(store.X, hovered.Window)
-		}
-	})
+	// Hovered client window has changed in the meantime
+	if hovered != tr.ClientAt(ws, store.Pointer.Position) {
+		return
+	}
+
+	// Focus hovered client window
+	if hovered != active && ws.TilingEnabled() && !tr.Handlers.Active() {
+		store.ActiveWindowSet(store.X, hovered.Window)
+	}
+}
+
+func interval() time.Duration {
+	if store.Idle.Active {
+		return 1000
+	}
+	return time.Duration(common.Power().Profile.PollInterval)
 }
 
-func poll(t time.Duration, fun func()) {
+func poll(t func() time.Duration, fun func()) {
 	go func() {
-		for range time.Tick(t * time.Millisecond) {
+		for {
+			time.Sleep(t() * time.Millisecond)
 			fun()
 		}
 	}()
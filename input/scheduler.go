@@ -0,0 +1,80 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Batch struct {
+	Count int         // Number of repeated actions
+	Timer *time.Timer // Timer to flush batched actions
+}
+
+var (
+	batches         = map[string]*Batch{} // Pending batches of repeated actions
+	repeatedActions = []string{
+		"proportion_increase",
+		"proportion_decrease",
+		"window_proportion_increase",
+		"window_proportion_decrease",
+	}
+)
+
+func schedule(tr *desktop.Tracker, action string, mod string, source string) {
+	delay := common.Config.TilingRepeat
+	if delay <= 0 || !common.IsInList(action, repeatedActions) {
+		ExecuteActions(action, tr, mod, source)
+		return
+	}
+
+	// Execute first action immediately
+	key := action + "-" + mod
+	if batch, ok := batches[key]; ok {
+		batch.Count += 1
+		return
+	}
+	ExecuteActions(action, tr, mod, source)
+
+	// Flush repeated actions periodically while they arrive
+	batch := &Batch{}
+	batches[key] = batch
+	var flush func()
+	flush = func() {
+		tr.Do(func() {
+			if batches[key] != batch {
+				return
+			}
+			if batch.Count == 0 {
+				delete(batches, key)
+				return
+			}
+			log.Debug("Execute batch of ", batch.Count, " repeated actions ", action)
+			ExecuteActions(fmt.Sprintf("%s %d", action, batch.Count), tr, mod, source)
+			batch.Count = 0
+			batch.Timer = time.AfterFunc(time.Duration(delay)*time.Millisecond, flush)
+		})
+	}
+	batch.Timer = time.AfterFunc(time.Duration(delay)*time.Millisecond, flush)
+}
+
+func repeats(value string) int {
+	if len(value) == 0 {
+		return 1
+	}
+
+	// Parse number of repetitions
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 1 {
+		log.Warn("Error parsing repetitions \"", value, "\"")
+		return 1
+	}
+
+	return count
+}
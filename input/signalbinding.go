@@ -6,7 +6,12 @@ import (
 
 	"os/signal"
 
+	"github.com/jezek/xgb/xproto"
+
 	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
 )
 
 func BindSignal(tr *desktop.Tracker) {
@@ -15,9 +20,15 @@ func BindSignal(tr *desktop.Tracker) {
 	// Bind signal channel
 	signal.Notify(ch, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	go exit(ch, tr)
+
+	// Attach selection events
+	store.OnSelectionUpdate(func(owner xproto.Window) {
+		log.Warn("Replaced by new instance [", owner, "]")
+		tr.Do(func() { ExecuteAction("exit", tr, tr.ActiveWorkspace(), "signal") })
+	})
 }
 
 func exit(ch chan os.Signal, tr *desktop.Tracker) {
 	<-ch
-	ExecuteAction("exit", tr, tr.ActiveWorkspace())
+	tr.Exec(func() { ExecuteAction("exit", tr, tr.ActiveWorkspace(), "signal") })
 }
@@ -40,6 +40,13 @@ func BindTray(tr *desktop.Tracker) {
 	// Start systray icon
 	go systray.Run(func() {
 		items(tr)
+
+		// Show current state on late attach
+		tr.Exec(func() {
+			ui.UpdateIcon(tr.ActiveWorkspace())
+			onActivate(tr)
+		})
+
 		messages(tr)
 	}, func() {})
 
@@ -48,6 +55,11 @@ func BindTray(tr *desktop.Tracker) {
 		onExecute(tr, action)
 	})
 
+	// Update tooltip countdown
+	poll(func() time.Duration { return 1000 }, func() {
+		tr.Exec(func() { ui.UpdateTooltip(tr.ActiveWorkspace()) })
+	})
+
 	// Attach pointer events
 	store.OnPointerUpdate(func(pointer store.XPointer, desktop uint, screen uint) {
 		onPointerClick(tr, pointer)
@@ -211,7 +223,7 @@ func items(tr *desktop.Tracker) {
 		go func(action string) {
 			for {
 				<-item.ClickedCh
-				ExecuteAction(action, tr, tr.ActiveWorkspace())
+				tr.Exec(func() { ExecuteAction(action, tr, tr.ActiveWorkspace(), "systray") })
 			}
 		}(action)
 	}
@@ -264,7 +276,7 @@ func messages(tr *desktop.Tracker) {
 			switch method {
 			case "Activate", "SecondaryActivate", "AboutToShow", "AboutToShowGroup":
 				clicked = true
-				onActivate(tr)
+				tr.Exec(func() { onActivate(tr) })
 			case "Scroll":
 				onPointerScroll(tr, msg.Body[0].(int32), strings.ToLower(msg.Body[1].(string)))
 			}
@@ -273,7 +285,7 @@ func messages(tr *desktop.Tracker) {
 }
 
 func onExecute(tr *desktop.Tracker, action string) {
-	if !common.IsInList(action, []string{"enable", "disable", "toggle", "decoration", "restore", "reset"}) {
+	if !common.IsInList(action, []string{"enable", "disable", "toggle", "pause", "decoration", "restore", "reset", "primary"}) {
 		return
 	}
 	onActivate(tr)
@@ -341,16 +353,18 @@ func onPointerClick(tr *desktop.Tracker, pointer store.XPointer) {
 
 	// Wait for dbus events
 	click = time.AfterFunc(150*time.Millisecond, func() {
-		if clicked && button.Left {
-			ExecuteAction(common.Config.Systray["click_left"], tr, tr.ActiveWorkspace())
-		}
-		if clicked && button.Middle {
-			ExecuteAction(common.Config.Systray["click_middle"], tr, tr.ActiveWorkspace())
-		}
-		if clicked && button.Right {
-			ExecuteAction(common.Config.Systray["click_right"], tr, tr.ActiveWorkspace())
-		}
-		clicked = false
+		tr.Exec(func() {
+			if clicked && button.Left {
+				ExecuteAction(common.Config.Systray["click_left"], tr, tr.ActiveWorkspace(), "systray")
+			}
+			if clicked && button.Middle {
+				ExecuteAction(common.Config.Systray["click_middle"], tr, tr.ActiveWorkspace(), "systray")
+			}
+			if clicked && button.Right {
+				ExecuteAction(common.Config.Systray["click_right"], tr, tr.ActiveWorkspace(), "systray")
+			}
+			clicked = false
+		})
 	})
 }
 
@@ -363,19 +377,23 @@ func onPointerScroll(tr *desktop.Tracker, delta int32, orientation string) {
 
 	// Compress scroll events
 	click = time.AfterFunc(150*time.Millisecond, func() {
-		switch orientation {
-		case "vertical":
-			if delta >= 0 {
-				ExecuteAction(common.Config.Systray["scroll_down"], tr, tr.ActiveWorkspace())
-			} else {
-				ExecuteAction(common.Config.Systray["scroll_up"], tr, tr.ActiveWorkspace())
-			}
-		case "horizontal":
-			if delta >= 0 {
-				ExecuteAction(common.Config.Systray["scroll_right"], tr, tr.ActiveWorkspace())
-			} else {
-				ExecuteAction(common.Config.Systray["scroll_left"], tr, tr.ActiveWorkspace())
-			}
-		}
+		tr.Exec(func() { scroll(tr, delta, orientation) })
 	})
 }
+
+func scroll(tr *desktop.Tracker, delta int32, orientation string) {
+	switch orientation {
+	case "vertical":
+		if delta >= 0 {
+			ExecuteAction(common.Config.Systray["scroll_down"], tr, tr.ActiveWorkspace(), "systray")
+		} else {
+			ExecuteAction(common.Config.Systray["scroll_up"], tr, tr.ActiveWorkspace(), "systray")
+		}
+	case "horizontal":
+		if delta >= 0 {
+			ExecuteAction(common.Config.Systray["scroll_right"], tr, tr.ActiveWorkspace(), "systray")
+		} else {
+			ExecuteAction(common.Config.Systray["scroll_left"], tr, tr.ActiveWorkspace(), "systray")
+		}
+	}
+}
@@ -58,11 +58,11 @@ func (l *HorizontalLayout) Reset() {
 func (l *HorizontalLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := store.GapSize(l.Location.Screen)
 
-	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	mmax := l.Fit(l.Masters, dw, gap, true)
+	smax := l.Fit(l.Slaves, dw, gap, true)
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -165,13 +165,13 @@ func (l *HorizontalLayout) Apply() {
 }
 
 func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Directions) {
-	_, _, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
+	_, _, dw, dh := l.Geometry().Pieces()
 	_, _, cw, ch := c.OuterGeometry()
 
-	gap := common.Config.WindowGapSize
+	gap := store.GapSize(l.Location.Screen)
 
-	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	mmax := l.Fit(l.Masters, dw, gap, true)
+	smax := l.Fit(l.Slaves, dw, gap, true)
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -199,7 +199,7 @@ func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Direction
 
 		// Set master-slave proportions
 		if d.Top {
-			l.Manager.SetProportions(l.Proportions.MasterSlave[2], py, idxms, idxms^1)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], l.Manager.SnapProportion(py), idxms, idxms^1)
 		}
 
 		// Set master-master proportions
@@ -215,7 +215,7 @@ func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Direction
 
 		// Set master-slave proportions
 		if d.Bottom {
-			l.Manager.SetProportions(l.Proportions.MasterSlave[2], py, idxms, idxms^1)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], l.Manager.SnapProportion(py), idxms, idxms^1)
 		}
 
 		// Set slave-slave proportions
@@ -3,7 +3,6 @@ package layout
 import (
 	"math"
 
-	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/store"
 
 	log "github.com/sirupsen/logrus"
@@ -33,8 +32,8 @@ func (l *MaximizedLayout) Reset() {
 func (l *MaximizedLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := store.GapSize(l.Location.Screen)
 
 	csize := len(clients)
 
@@ -58,11 +58,11 @@ func (l *VerticalLayout) Reset() {
 func (l *VerticalLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := store.GapSize(l.Location.Screen)
 
-	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	mmax := l.Fit(l.Masters, dh, gap, false)
+	smax := l.Fit(l.Slaves, dh, gap, false)
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -165,13 +165,13 @@ func (l *VerticalLayout) Apply() {
 }
 
 func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions) {
-	_, _, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
+	_, _, dw, dh := l.Geometry().Pieces()
 	_, _, cw, ch := c.OuterGeometry()
 
-	gap := common.Config.WindowGapSize
+	gap := store.GapSize(l.Location.Screen)
 
-	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	mmax := l.Fit(l.Masters, dh, gap, false)
+	smax := l.Fit(l.Slaves, dh, gap, false)
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -199,7 +199,7 @@ func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions)
 
 		// Set master-slave proportions
 		if d.Left {
-			l.Manager.SetProportions(l.Proportions.MasterSlave[2], px, idxms, idxms^1)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], l.Manager.SnapProportion(px), idxms, idxms^1)
 		}
 
 		// Set master-master proportions
@@ -215,7 +215,7 @@ func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions)
 
 		// Set master-slave proportions
 		if d.Right {
-			l.Manager.SetProportions(l.Proportions.MasterSlave[2], px, idxms, idxms^1)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], l.Manager.SnapProportion(px), idxms, idxms^1)
 		}
 
 		// Set slave-slave proportions
@@ -6,14 +6,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"syscall"
+	"time"
 
+	"encoding/json"
 	"runtime/debug"
 
-	"github.com/jezek/xgbutil/xevent"
-
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/harness"
 	"github.com/leukipp/cortile/v2/input"
 	"github.com/leukipp/cortile/v2/store"
 	"github.com/leukipp/cortile/v2/ui"
@@ -66,6 +68,27 @@ func main() {
 	// Run dbus instance
 	runDbus()
 
+	// Run cache instance
+	runCache()
+
+	// Run rules instance
+	runRules()
+
+	// Run keys instance
+	runKeys()
+
+	// Run inspect instance
+	runInspect()
+
+	// Run menu instance
+	runMenu()
+
+	// Run stats instance
+	runStats()
+
+	// Run bench instance
+	runBench()
+
 	// Run main instance
 	runMain()
 }
@@ -97,6 +120,210 @@ func runDbus() {
 	}
 }
 
+func runCache() {
+	command := common.Args.Caches.Command
+	if len(command) == 0 {
+		return
+	}
+
+	// Load config quietly
+	log.SetLevel(log.WarnLevel)
+	common.LoadConfig()
+
+	// Execute cache command
+	switch command {
+	case "prune":
+		report := common.PruneCache("")
+		fmt.Printf("PRUNE: \n  workplaces: %d\n  clients: %d\n  size: %d bytes\n", report.Workplaces, report.Files, report.Bytes)
+	case "ls":
+		workplace, typ := "", ""
+		for _, entry := range common.CacheEntries() {
+			if entry.Workplace != workplace {
+				fmt.Printf("WORKPLACE: %s\n", entry.Workplace)
+				workplace, typ = entry.Workplace, ""
+			}
+			if entry.Type != typ {
+				fmt.Printf("  %s:\n", entry.Type)
+				typ = entry.Type
+			}
+			fmt.Printf("    %s  %s\n", entry.Id, entry.Summary())
+		}
+	case "show":
+		for _, id := range common.Args.Caches.P {
+			entry, ok := common.CacheEntryGet(id)
+			if !ok {
+				fmt.Println(fmt.Errorf("cache entry \"%s\" not found", id))
+				os.Exit(1)
+			}
+			data, _ := os.ReadFile(entry.Path)
+			if !entry.Corrupt {
+				data, _ = json.MarshalIndent(entry.Data, "", "  ")
+			}
+			fmt.Printf("%s:\n%s\n", entry.Path, string(data))
+		}
+	case "rm":
+		for _, entry := range common.CacheEntries() {
+			corrupt := entry.Corrupt && common.IsInList("corrupt", common.Args.Caches.P)
+			if !corrupt && !common.IsInList(entry.Id, common.Args.Caches.P) {
+				continue
+			}
+			if err := os.Remove(entry.Path); err != nil {
+				fmt.Println(fmt.Errorf("FILE error (%s)", err))
+				continue
+			}
+			fmt.Printf("removed %s\n", entry.Path)
+		}
+	case "export", "import":
+		archive := fmt.Sprintf("%s-cache.tar.gz", common.Build.Name)
+		if len(common.Args.Caches.P) > 0 {
+			archive = common.Args.Caches.P[0]
+		}
+		transfer := common.ExportCache
+		if command == "import" {
+			transfer = common.ImportCache
+		}
+		count, err := transfer(archive)
+		if err != nil {
+			fmt.Println(fmt.Errorf("FILE error (%s)", err))
+			os.Exit(1)
+		}
+		fmt.Printf("%s: \n  archive: %s\n  files: %d\n", strings.ToUpper(command), archive, count)
+	default:
+		fmt.Println(fmt.Errorf("unknown cache command \"%s\"", command))
+		os.Exit(2)
+	}
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runRules() {
+	command := common.Args.Rules.Command
+	if len(command) == 0 {
+		return
+	}
+
+	// Query window rules of running instance
+	id := "0"
+	if len(common.Args.Rules.P) > 0 {
+		id = common.Args.Rules.P[0]
+	}
+	input.Method("RulesTest", []string{id})
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runKeys() {
+	command := common.Args.Keys.Command
+	if len(command) == 0 {
+		return
+	}
+
+	// Query or change key bindings of running instance
+	persist := "0"
+	if common.Args.Keys.Persist {
+		persist = "1"
+	}
+	switch command {
+	case "list":
+		input.Method("KeyList", []string{})
+	case "add":
+		if len(common.Args.Keys.P) != 2 {
+			fmt.Println(fmt.Errorf("keys add requires an action and a key"))
+			os.Exit(2)
+		}
+		input.Method("KeyAdd", append(common.Args.Keys.P, persist))
+	case "remove":
+		if len(common.Args.Keys.P) != 1 {
+			fmt.Println(fmt.Errorf("keys remove requires an action"))
+			os.Exit(2)
+		}
+		input.Method("KeyRemove", append(common.Args.Keys.P, persist))
+	case "doctor":
+		input.Method("KeysDoctor", []string{})
+	}
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runMenu() {
+	command := common.Args.Menu.Command
+	if len(command) == 0 {
+		return
+	}
+
+	// List or execute menu entries of running instance
+	input.Launcher(command, strings.Join(common.Args.Menu.P, " "))
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runInspect() {
+	if !common.Args.Inspect.Enabled {
+		return
+	}
+
+	// Select window by id or pointer click
+	id := "0"
+	if len(common.Args.Inspect.P) > 0 {
+		id = common.Args.Inspect.P[0]
+	}
+	if common.Args.Inspect.Click {
+		w, err := store.SelectWindow()
+		if err != nil {
+			fmt.Println(fmt.Errorf("X error (%s)", err))
+			os.Exit(1)
+		}
+		id = fmt.Sprint(w)
+	}
+
+	// Query window information of running instance
+	input.Method("WindowInspect", []string{id})
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runStats() {
+	if !common.Args.Stats.Enabled {
+		return
+	}
+
+	// Query usage statistics of running instance
+	input.Method("Statistics", []string{})
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runBench() {
+	if !common.Args.Bench.Enabled {
+		return
+	}
+
+	// Load config quietly
+	log.SetLevel(log.WarnLevel)
+	common.LoadConfig()
+
+	// Execute bench command
+	config, err := os.ReadFile(common.Args.Config)
+	if err != nil {
+		config = toml
+	}
+	report, err := harness.Bench(common.Args.Bench.Windows, common.Args.Bench.Iterations, common.Args.Bench.Simulate, config)
+	if err != nil {
+		fmt.Println(fmt.Errorf("BENCH error (%s)", err))
+		os.Exit(1)
+	}
+	fmt.Print(report)
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
 func runMain() {
 	defer func() {
 		if err := recover(); err != nil {
@@ -106,19 +333,35 @@ func runMain() {
 
 	// Init lock and log files
 	defer InitLock().Close()
+	common.InitSystemd()
 	InitLog()
 
-	// Init cache and config
+	// Init cache, config and power
 	common.InitCache()
 	common.InitConfig()
+	common.InitPower()
+
+	// Init display server connection
+	switch common.Args.Backend {
+	case "auto", "x11":
+		store.InitRoot()
+	case "wlroots":
+		// Run experimental backend without tracker
+		backend := store.InitWlroots()
+		common.NotifySystemd("READY=1")
+		backend.Loop()
+		return
+	default:
+		log.Fatal("Unknown backend \"", common.Args.Backend, "\"")
+	}
 
-	// Init root properties
-	store.InitRoot()
+	// Prune outdated cache entries
+	go common.PruneCache(store.Workplace.Displays.Name)
 
 	// Create tracker instance
 	tr := desktop.CreateTracker()
 	input.Bind(tr)
-	tr.Update()
+	tr.Exec(tr.Update)
 
 	// Show layout overlay
 	ws := tr.ActiveWorkspace()
@@ -126,38 +369,67 @@ func runMain() {
 		ui.ShowLayout(ws)
 	}
 
+	// Notify service manager
+	common.NotifySystemd("READY=1")
+	common.WatchSystemd(tr.Alive)
+
+	// Reconnect on X server loss
+	store.OnDisconnect(func() {
+		input.Reconnect(tr)
+	})
+
 	// Run X event loop
-	xevent.Main(store.X)
+	store.Loop()
 }
 
 func InitLock() *os.File {
 	file, err := createLockFile(common.Args.Lock)
+
+	// Take over running instance
+	if err != nil && common.Args.Replace && store.ReplaceInstance() {
+		for i := 0; i < 50 && err != nil; i++ {
+			time.Sleep(100 * time.Millisecond)
+			file, err = createLockFile(common.Args.Lock)
+		}
+	}
+
 	if err != nil {
-		fmt.Println(fmt.Errorf("%s already running (%s)", common.Build.Name, err))
+		fmt.Println(fmt.Errorf("%s already running (%s), use -replace to take over", common.Build.Name, err))
 		os.Exit(1)
 	}
 
 	return file
 }
 
-func InitLog() *os.File {
+func InitLog() *common.LogFile {
+	level := log.WarnLevel
 	if common.Args.VVV {
-		log.SetLevel(log.TraceLevel)
+		level = log.TraceLevel
 	} else if common.Args.VV {
-		log.SetLevel(log.DebugLevel)
+		level = log.DebugLevel
 	} else if common.Args.V {
-		log.SetLevel(log.InfoLevel)
-	} else {
-		log.SetLevel(log.WarnLevel)
+		level = log.InfoLevel
+	}
+	if common.Args.DryRun && level < log.InfoLevel {
+		level = log.InfoLevel
+	}
+	common.InitLogging(level, common.Args.LogFormat)
+
+	// Log structured entries to journald
+	stderr := io.Writer(os.Stderr)
+	if common.Systemd.Journal {
+		log.SetReportCaller(true)
+		log.AddHook(common.CreateJournalHook())
+		log.SetOutput(io.Discard)
+		stderr = io.Discard
 	}
-	log.SetFormatter(&log.TextFormatter{ForceColors: true, FullTimestamp: true})
 
 	file, err := createLogFile(common.Args.Log)
 	if err != nil {
 		return file
 	}
 
-	log.SetOutput(io.MultiWriter(os.Stderr, file))
+	log.SetOutput(io.MultiWriter(stderr, file))
 	log.RegisterExitHandler(func() {
 		if file != nil {
 			file.Close()
@@ -183,8 +455,8 @@ func createLockFile(filename string) (*os.File, error) {
 	return file, nil
 }
 
-func createLogFile(filename string) (*os.File, error) {
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+func createLogFile(filename string) (*common.LogFile, error) {
+	file, err := common.CreateLogFile(filename)
 	if err != nil {
 		fmt.Println(fmt.Errorf("FILE error (%s)", err))
 		return nil, err
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgbutil/ewmh"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Animation struct {
+	Stop    chan bool       // Channel to cancel running animation
+	Current common.Geometry // Latest interpolated request geometry
+	Target  common.Geometry // Final request geometry
+	Running bool            // Animation steps are pending
+	Lock    sync.Mutex      // Lock for concurrent state access
+}
+
+func (c *Client) animate(x, y, w, h int) bool {
+	duration := common.Config.WindowAnimation
+	steps := common.Config.WindowAnimationSteps
+
+	// Keep running animation towards same target
+	to := common.Geometry{X: x, Y: y, Width: w, Height: h}
+	if c.Animating() && c.Animation.Target == to {
+		return true
+	}
+
+	// Cancel running animation
+	from, running := c.stopAnimation()
+	if duration <= 0 || steps <= 1 || !animationsEnabled() {
+		return false
+	}
+
+	// Start from current request geometry
+	if !running {
+		ext := c.Latest.Dimensions.Extents
+		dx, dy, dw, dh := 0, 0, 0, 0
+		if c.Latest.Dimensions.AdjPos {
+			dx, dy = ext.Left, ext.Top
+		}
+		if c.Latest.Dimensions.AdjSize {
+			dw, dh = ext.Left+ext.Right, ext.Top+ext.Bottom
+		}
+		g := c.Latest.Dimensions.Geometry
+		from = common.Geometry{X: g.X + dx, Y: g.Y + dy, Width: g.Width - dw, Height: g.Height - dh}
+	}
+	if from == to || from.Width <= 0 || from.Height <= 0 {
+		return false
+	}
+
+	// Interpolate geometries in background
+	a := &Animation{Stop: make(chan bool), Current: from, Target: to, Running: true}
+	c.Animation = a
+	go func() {
+		interval := time.Duration(duration/steps) * time.Millisecond
+		for i := 1; i <= steps; i++ {
+			select {
+			case <-a.Stop:
+				return
+			case <-time.After(interval):
+			}
+
+			// Ease out quadratic or jump to target when disabled meanwhile
+			t := float64(i) / float64(steps)
+			if !animationsEnabled() {
+				t, i = 1.0, steps
+			}
+			f := 1 - (1-t)*(1-t)
+			g := common.Geometry{
+				X:      from.X + int(math.Round(f*float64(to.X-from.X))),
+				Y:      from.Y + int(math.Round(f*float64(to.Y-from.Y))),
+				Width:  from.Width + int(math.Round(f*float64(to.Width-from.Width))),
+				Height: from.Height + int(math.Round(f*float64(to.Height-from.Height))),
+			}
+
+			// Request step unless stopped meanwhile
+			a.Lock.Lock()
+			if !a.Running {
+				a.Lock.Unlock()
+				return
+			}
+			a.Current = g
+			a.Running = i < steps
+			c.request(g.X, g.Y, g.Width, g.Height)
+			a.Lock.Unlock()
+		}
+	}()
+
+	return true
+}
+
+func animationsEnabled() bool {
+	return common.Power().Profile.Animations && !PointerDragging(500)
+}
+
+func (c *Client) Animating() bool {
+	if c.Animation == nil {
+		return false
+	}
+
+	c.Animation.Lock.Lock()
+	defer c.Animation.Lock.Unlock()
+
+	return c.Animation.Running
+}
+
+func (c *Client) stopAnimation() (common.Geometry, bool) {
+	a := c.Animation
+	if a == nil {
+		return common.Geometry{}, false
+	}
+	c.Animation = nil
+
+	a.Lock.Lock()
+	defer a.Lock.Unlock()
+
+	// Cancel pending animation steps
+	if !a.Running {
+		return a.Current, false
+	}
+	a.Running = false
+	close(a.Stop)
+
+	return a.Current, true
+}
+
+func (c *Client) request(x, y, w, h int) {
+	if Capable("moveresize") {
+		ewmh.MoveresizeWindow(X, c.Window.Id, x, y, w, h)
+	} else {
+		c.Window.Instance.MoveResize(x, y, w, h)
+	}
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/ewmh"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Capability struct {
+	Name  string                                   // Capability name used for config overrides
+	Probe func(wm string, supported []string) bool // Detection by window manager name and supported atoms
+}
+
+var (
+	capabilities = []Capability{{
+		Name: "size_hints", // Window size limits via WM_NORMAL_HINTS are respected
+		Probe: func(wm string, supported []string) bool {
+			return !strings.Contains(wm, "mutter") && !strings.Contains(wm, "muffin")
+		},
+	}, {
+		Name: "frame_extents", // Server side decorations are reported via _NET_FRAME_EXTENTS
+		Probe: func(wm string, supported []string) bool {
+			return common.IsInList("_NET_FRAME_EXTENTS", supported)
+		},
+	}, {
+		Name: "moveresize", // Windows are moved via _NET_MOVERESIZE_WINDOW requests
+		Probe: func(wm string, supported []string) bool {
+			return common.IsInList("_NET_MOVERESIZE_WINDOW", supported)
+		},
+	}, {
+		Name: "frame_correction", // Decoration offsets are learned from requested and resulting geometries
+		Probe: func(wm string, supported []string) bool {
+			return strings.Contains(wm, "openbox") || strings.Contains(wm, "xfwm") || strings.Contains(wm, "marco")
+		},
+	}, {
+		Name: "struts", // Panel struts are subtracted from desktop geometries
+		Probe: func(wm string, supported []string) bool {
+			return common.IsInList("_NET_WM_STRUT_PARTIAL", supported) || common.IsInList("_NET_WM_STRUT", supported)
+		},
+	}, {
+		Name: "pointer_tracking", // Pointer position is reported above all windows (hot corners, hover focus)
+		Probe: func(wm string, supported []string) bool {
+			return !WindowManager.XWayland
+		},
+	}, {
+		Name: "desktops", // Number of desktops can be changed via _NET_NUMBER_OF_DESKTOPS
+		Probe: func(wm string, supported []string) bool {
+			return common.IsInList("_NET_NUMBER_OF_DESKTOPS", supported)
+		},
+	}} // Capabilities controlling window manager workarounds
+)
+
+func InitCapabilities() {
+	wm := strings.ToLower(WindowManager.Name)
+	supported, err := ewmh.SupportedGet(X)
+	if err != nil {
+		log.Warn("Error retrieving supported atoms: ", err)
+	}
+
+	// Detect xwayland sessions
+	WindowManager.XWayland = XWaylandGet()
+
+	// Probe window manager capabilities
+	WindowManager.Supported = supported
+	WindowManager.Capabilities = make(map[string]bool)
+	for _, capability := range capabilities {
+		WindowManager.Capabilities[capability.Name] = capability.Probe(wm, supported)
+	}
+
+	log.Info("Capabilities ", CapabilitiesGet())
+
+	// Report restricted capabilities
+	if WindowManager.XWayland {
+		disabled := []string{}
+		for _, capability := range capabilities {
+			if !Capable(capability.Name) {
+				disabled = append(disabled, capability.Name)
+			}
+		}
+		log.Warn("Running inside XWayland, only X11 windows are visible and tiled, disabled ", disabled)
+	}
+}
+
+func Capable(name string) bool {
+
+	// Check config overrides
+	if value, ok := common.Config.Capabilities[name]; ok {
+		return value
+	}
+
+	// Check probed capabilities
+	if WindowManager == nil {
+		return true
+	}
+	if value, ok := WindowManager.Capabilities[name]; ok {
+		return value
+	}
+
+	return true
+}
+
+func CapabilitiesGet() []string {
+	names := []string{}
+
+	// List enabled capabilities
+	for _, capability := range capabilities {
+		if Capable(capability.Name) {
+			names = append(names, capability.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func XWaylandGet() bool {
+
+	// Check for xwayland extension
+	name := "XWAYLAND"
+	extension, err := xproto.QueryExtension(X.Conn(), uint16(len(name)), name).Reply()
+	if err == nil && extension.Present {
+		return true
+	}
+
+	// Check for xwayland output names
+	if !Extension(X, "RANDR") {
+		return false
+	}
+	resources, err := randr.GetScreenResources(X.Conn(), X.RootWin()).Reply()
+	if err != nil {
+		return false
+	}
+	for _, output := range resources.Outputs {
+		oinfo, err := randr.GetOutputInfo(X.Conn(), output, 0).Reply()
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(string(oinfo.Name), name) {
+			return true
+		}
+	}
+
+	return false
+}
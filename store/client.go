@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"os"
 	"reflect"
-	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"encoding/json"
@@ -26,22 +26,32 @@ import (
 )
 
 type Client struct {
-	Window   *XWindow // X window object
-	Original *Info    `json:"-"` // Original client window information
-	Cached   *Info    `json:"-"` // Cached client window information
-	Latest   *Info    // Latest client window information
-	Locked   bool     // Internal client move/resize lock
+	Window    *XWindow   // X window object
+	Original  *Info      `json:"-"` // Original client window information
+	Cached    *Info      `json:"-"` // Cached client window information
+	Latest    *Info      // Latest client window information
+	Locked    bool       // Internal client move/resize lock
+	Decorated string     `json:"-"` // Decoration override of rules (keep, strip)
+	Toggled   string     // Decoration override of manual toggle (keep, strip)
+	Moved     Moved      `json:"-"` // Latest move/resize request
+	Animation *Animation `json:"-"` // Running move/resize animation
 }
 
 type Info struct {
 	Class      string     // Client window application name
 	Name       string     // Client window title name
+	Process    Process    // Client window process
 	Types      []string   // Client window types
 	States     []string   // Client window states
 	Location   Location   // Client window location
 	Dimensions Dimensions // Client window dimensions
 }
 
+type Process struct {
+	Id   uint   // Process id from _NET_WM_PID
+	Name string // Process executable name
+}
+
 type Dimensions struct {
 	Geometry   common.Geometry   // Client window geometry
 	Hints      Hints             // Client window dimension hints
@@ -56,6 +66,28 @@ type Hints struct {
 	Motif  motif.Hints       // Client window decoration hints
 }
 
+type XGeometryCookies struct {
+	Geometry  xproto.GetGeometryCookie          // Window geometry request
+	Translate xproto.TranslateCoordinatesCookie // Window root position request
+}
+
+type XSessions struct {
+	Created   map[xproto.Window]*Client // Clients created within this session
+	Processes map[xproto.Window]Process // Resolved processes of windows
+	Missing   map[string]bool           // Cache files known to be absent
+	Folders   map[string]bool           // Cache folders known to exist
+	Lock      sync.Mutex                // Lock for concurrent access
+}
+
+var (
+	Sessions = &XSessions{Created: map[xproto.Window]*Client{}, Processes: map[xproto.Window]Process{}, Missing: map[string]bool{}, Folders: map[string]bool{}} // Session state of clients
+)
+
+var (
+	infoWorkers   = 4  // Maximum number of parallel window info requests
+	infoThreshold = 16 // Minimum number of windows to request infos in parallel
+)
+
 const (
 	Original uint8 = 1 // Flag to restore original info
 	Cached   uint8 = 2 // Flag to restore cached info
@@ -63,16 +95,32 @@ const (
 )
 
 func CreateClient(w xproto.Window) *Client {
+	info := GetInfo(w)
 	c := &Client{
 		Window:   CreateXWindow(w),
-		Original: GetInfo(w),
-		Cached:   GetInfo(w),
-		Latest:   GetInfo(w),
+		Original: info.Copy(),
+		Cached:   info.Copy(),
+		Latest:   info,
 		Locked:   false,
 	}
 
-	// Read client from cache
-	cached := c.Read()
+	// Reuse session state of re-tracked windows
+	Sessions.Lock.Lock()
+	previous, ok := Sessions.Created[w]
+	Sessions.Created[w] = c
+	Sessions.Lock.Unlock()
+
+	// Read client from session or cache
+	cached := previous
+	if ok {
+		c.Original = previous.Original
+	} else {
+		cached = c.Read()
+	}
+
+	// Overwrite decoration overrides
+	c.Decorated = DecorationGet(c.Latest)
+	c.Toggled = cached.Toggled
 
 	// Overwrite states, geometry and location
 	c.Cached.States = cached.Latest.States
@@ -98,7 +146,7 @@ func (c *Client) UnLock() {
 }
 
 func (c *Client) Limit(w, h int) bool {
-	if !Compatible("icccm.SizeHintPMinSize") {
+	if !Capable("size_hints") {
 		return false
 	}
 
@@ -111,24 +159,30 @@ func (c *Client) Limit(w, h int) bool {
 	nhints.Flags |= icccm.SizeHintPMinSize
 	nhints.MinWidth = uint(w - dw)
 	nhints.MinHeight = uint(h - dh)
+	if c.simulated("WmNormalHintsSet", int(nhints.MinWidth), int(nhints.MinHeight)) {
+		return true
+	}
 	icccm.WmNormalHintsSet(X, c.Window.Id, &nhints)
 
 	return true
 }
 
 func (c *Client) UnLimit() bool {
-	if !Compatible("icccm.SizeHintPMinSize") {
+	if !Capable("size_hints") {
 		return false
 	}
 
 	// Restore window size limits
+	if c.simulated("WmNormalHintsSet", int(c.Cached.Dimensions.Hints.Normal.MinWidth), int(c.Cached.Dimensions.Hints.Normal.MinHeight)) {
+		return true
+	}
 	icccm.WmNormalHintsSet(X, c.Window.Id, &c.Cached.Dimensions.Hints.Normal)
 
 	return true
 }
 
 func (c *Client) Decorate() bool {
-	if _, exists := common.Config.Keys["decoration"]; !exists {
+	if _, exists := common.Config.Keys["decoration"]; !exists && len(c.override()) == 0 {
 		return false
 	}
 	if motif.Decor(&c.Latest.Dimensions.Hints.Motif) || !motif.Decor(&c.Original.Dimensions.Hints.Motif) {
@@ -139,13 +193,16 @@ func (c *Client) Decorate() bool {
 	mhints := c.Cached.Dimensions.Hints.Motif
 	mhints.Flags |= motif.HintDecorations
 	mhints.Decoration = motif.DecorationAll
+	if c.simulated("WmHintsSet", int(mhints.Decoration)) {
+		return true
+	}
 	motif.WmHintsSet(X, c.Window.Id, &mhints)
 
 	return true
 }
 
 func (c *Client) UnDecorate() bool {
-	if _, exists := common.Config.Keys["decoration"]; !exists {
+	if _, exists := common.Config.Keys["decoration"]; !exists && len(c.override()) == 0 {
 		return false
 	}
 	if !motif.Decor(&c.Latest.Dimensions.Hints.Motif) && motif.Decor(&c.Original.Dimensions.Hints.Motif) {
@@ -156,17 +213,71 @@ func (c *Client) UnDecorate() bool {
 	mhints := c.Cached.Dimensions.Hints.Motif
 	mhints.Flags |= motif.HintDecorations
 	mhints.Decoration = motif.DecorationNone
+	if c.simulated("WmHintsSet", int(mhints.Decoration)) {
+		return true
+	}
 	motif.WmHintsSet(X, c.Window.Id, &mhints)
 
 	return true
 }
 
+func (c *Client) Decoration(enabled bool) bool {
+	switch c.override() {
+	case "keep":
+		return true
+	case "strip":
+		return false
+	}
+	return enabled
+}
+
+func (c *Client) override() string {
+
+	// Manual toggles take precedence over rules
+	if len(c.Toggled) > 0 {
+		return c.Toggled
+	}
+
+	return c.Decorated
+}
+
+func (c *Client) Raise() bool {
+
+	// Raise window above siblings
+	if c.simulated("RestackWindow", int(xproto.StackModeAbove)) {
+		return true
+	}
+	if Supported("_NET_RESTACK_WINDOW") {
+		ewmh.RestackWindow(X, c.Window.Id)
+		return true
+	}
+
+	// Raise frame window of reparenting window managers
+	if c.Window.Frame == 0 {
+		frame := c.Window.Id
+		for {
+			tree, err := xproto.QueryTree(X.Conn(), frame).Reply()
+			if err != nil || tree.Parent == 0 || tree.Parent == X.RootWin() {
+				break
+			}
+			frame = tree.Parent
+		}
+		c.Window.Frame = frame
+	}
+	xproto.ConfigureWindow(X.Conn(), c.Window.Frame, xproto.ConfigWindowStackMode, []uint32{uint32(xproto.StackModeAbove)})
+
+	return true
+}
+
 func (c *Client) Fullscreen() bool {
 	if IsFullscreen(c.Latest) {
 		return false
 	}
 
 	// Fullscreen window
+	if c.simulated("WmStateReq", ewmh.StateAdd) {
+		return true
+	}
 	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_FULLSCREEN")
 
 	return true
@@ -178,6 +289,9 @@ func (c *Client) UnFullscreen() bool {
 	}
 
 	// Unfullscreen window
+	if c.simulated("WmStateReq", ewmh.StateRemove) {
+		return true
+	}
 	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_FULLSCREEN")
 
 	return true
@@ -189,6 +303,9 @@ func (c *Client) UnMaximize() bool {
 	}
 
 	// Unmaximize window
+	if c.simulated("WmStateReq", ewmh.StateRemove) {
+		return true
+	}
 	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_VERT")
 	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_HORZ")
 
@@ -196,6 +313,9 @@ func (c *Client) UnMaximize() bool {
 }
 
 func (c *Client) MoveToDesktop(desktop uint32) bool {
+	if c.simulated("WmDesktopSet", int(desktop)) {
+		return false
+	}
 	if desktop == ^uint32(0) {
 		ewmh.WmStateReq(X, c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_STICKY")
 	}
@@ -215,12 +335,54 @@ func (c *Client) MoveToScreen(screen uint32) bool {
 	x, y := common.MaxInt(geom.Center().X-w/2, geom.X+100), common.MaxInt(geom.Center().Y-h/2, geom.Y+100)
 
 	// Move window and simulate tracker pointer press
+	if c.simulated("MoveWindow", x, y) {
+		return false
+	}
 	ewmh.MoveWindow(X, c.Window.Id, x, y)
 	Pointer.Press()
 
 	return true
 }
 
+func MoveWindowToDesktop(w xproto.Window, desktop uint32) bool {
+	if desktop >= uint32(Workplace.DesktopCount) {
+		return false
+	}
+	if common.Args.DryRun {
+		log.Info("Dry-run WmDesktopSet ", desktop, " [", w, "]")
+		return false
+	}
+
+	// Set untracked window desktop
+	ewmh.WmDesktopSet(X, w, uint(desktop))
+	ewmh.ClientEvent(X, w, "_NET_WM_DESKTOP", int(desktop), int(2))
+
+	return true
+}
+
+func MoveWindowToScreen(w xproto.Window, screen uint32) bool {
+	if screen >= uint32(Workplace.ScreenCount) {
+		return false
+	}
+	geom := Workplace.Displays.Screens[screen].Geometry
+
+	// Calculate move to position of untracked window
+	dGeom, err := xwindow.New(X, w).DecorGeometry()
+	if err != nil {
+		return false
+	}
+	x, y := common.MaxInt(geom.Center().X-dGeom.Width()/2, geom.X+100), common.MaxInt(geom.Center().Y-dGeom.Height()/2, geom.Y+100)
+
+	// Move untracked window
+	if common.Args.DryRun {
+		log.Info("Dry-run MoveWindow ", []int{x, y}, " [", w, "]")
+		return false
+	}
+	ewmh.MoveWindow(X, w, x, y)
+
+	return true
+}
+
 func (c *Client) MoveWindow(x, y, w, h int) {
 	if c.Locked {
 		log.Info("Reject window move/resize [", c.Latest.Class, "]")
@@ -234,6 +396,11 @@ func (c *Client) MoveWindow(x, y, w, h int) {
 	c.UnMaximize()
 	c.UnFullscreen()
 
+	// Skip unchanged geometries
+	if c.moved(common.Geometry{X: x, Y: y, Width: w, Height: h}) {
+		return
+	}
+
 	// Calculate dimension offsets
 	ext := c.Latest.Dimensions.Extents
 	dx, dy, dw, dh := 0, 0, 0, 0
@@ -245,15 +412,33 @@ func (c *Client) MoveWindow(x, y, w, h int) {
 		dw, dh = ext.Left+ext.Right, ext.Top+ext.Bottom
 	}
 
+	// Apply learned frame corrections
+	correction := c.Correction()
+	dx, dy = dx-correction.X, dy-correction.Y
+	dw, dh = dw+correction.Width, dh+correction.Height
+
 	// Move and/or resize window
 	if w > 0 && h > 0 {
-		ewmh.MoveresizeWindow(X, c.Window.Id, x+dx, y+dy, w-dw, h-dh)
+		if c.simulated("MoveresizeWindow", x+dx, y+dy, w-dw, h-dh) {
+			return
+		}
+		if !c.animate(x+dx, y+dy, w-dw, h-dh) {
+			c.request(x+dx, y+dy, w-dw, h-dh)
+		}
 	} else {
-		ewmh.MoveWindow(X, c.Window.Id, x+dx, y+dy)
+		if c.simulated("MoveWindow", x+dx, y+dy) {
+			return
+		}
+		c.stopAnimation()
+		if Capable("moveresize") {
+			ewmh.MoveWindow(X, c.Window.Id, x+dx, y+dy)
+		} else {
+			c.Window.Instance.Move(x+dx, y+dy)
+		}
 	}
 
 	// Update stored dimensions
-	c.Update()
+	c.applied()
 }
 
 func (c *Client) OuterGeometry() (x, y, w, h int) {
@@ -305,7 +490,7 @@ func (c *Client) Restore(flag uint8) {
 
 	// Restore window decorations
 	if flag == Original {
-		if common.Config.WindowDecoration {
+		if c.Decoration(common.Config.WindowDecoration) {
 			c.Decorate()
 		} else {
 			c.UnDecorate()
@@ -341,7 +526,7 @@ func (c *Client) Update() {
 	c.Latest = info
 }
 
-func (c *Client) Write() {
+func (c *Client) Write(batch *common.CacheBatch) {
 	if common.CacheDisabled() {
 		return
 	}
@@ -349,21 +534,35 @@ func (c *Client) Write() {
 	// Obtain cache object
 	cache := c.Cache()
 
+	// Omit window titles
+	if !common.Config.CacheNames {
+		latest := *c.Latest
+		latest.Name = ""
+		cache.Data = &Client{Window: c.Window, Latest: &latest, Locked: c.Locked, Toggled: c.Toggled}
+	}
+
 	// Parse client cache
-	data, err := json.MarshalIndent(cache.Data, "", "  ")
+	data, err := common.MarshalCache(cache.Data)
 	if err != nil {
 		log.Warn("Error parsing client cache [", c.Latest.Class, "]")
 		return
 	}
 
-	// Write client cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	err = os.WriteFile(path, data, 0644)
+	// Encrypt client cache
+	data, err = common.EncryptCache(data)
 	if err != nil {
-		log.Warn("Error writing client cache [", c.Latest.Class, "]")
+		log.Warn("Error encrypting client cache [", c.Latest.Class, "]")
 		return
 	}
 
+	// Add client cache to batch
+	path := filepath.Join(cache.Folder, cache.Name)
+	batch.Add(path, data)
+
+	Sessions.Lock.Lock()
+	delete(Sessions.Missing, path)
+	Sessions.Lock.Unlock()
+
 	log.Trace("Write client cache data ", cache.Name, " [", c.Latest.Class, "]")
 }
 
@@ -375,14 +574,39 @@ func (c *Client) Read() *Client {
 	// Obtain cache object
 	cache := c.Cache()
 
-	// Read client cache
+	// Skip cache files known to be absent
 	path := filepath.Join(cache.Folder, cache.Name)
-	data, err := os.ReadFile(path)
+	Sessions.Lock.Lock()
+	missing := Sessions.Missing[path]
+	Sessions.Lock.Unlock()
+	if missing {
+		return c
+	}
+
+	// Read client cache
+	data, err := common.ReadCache(path)
 	if os.IsNotExist(err) {
+		Sessions.Lock.Lock()
+		Sessions.Missing[path] = true
+		Sessions.Lock.Unlock()
 		log.Info("No client cache found [", c.Latest.Class, "]")
 		return c
 	}
 
+	// Decrypt client cache
+	data, err = common.DecryptCache(data)
+	if err != nil {
+		log.Warn("Error decrypting client cache [", c.Latest.Class, "]")
+		return c
+	}
+
+	// Migrate client cache
+	data, err = common.MigrateCache(path, data)
+	if err != nil {
+		log.Warn("Error migrating client cache [", c.Latest.Class, "]")
+		return c
+	}
+
 	// Parse client cache
 	cached := &Client{}
 	err = json.Unmarshal([]byte(data), &cached)
@@ -402,9 +626,14 @@ func (c *Client) Cache() common.Cache[*Client] {
 
 	// Create client cache folder
 	folder := filepath.Join(common.Args.Cache, "workplaces", Workplace.Displays.Name, "clients", subfolder)
-	if _, err := os.Stat(folder); os.IsNotExist(err) {
-		os.MkdirAll(folder, 0755)
+	Sessions.Lock.Lock()
+	if !Sessions.Folders[folder] {
+		if _, err := os.Stat(folder); os.IsNotExist(err) {
+			os.MkdirAll(folder, 0755)
+		}
+		Sessions.Folders[folder] = true
 	}
+	Sessions.Lock.Unlock()
 
 	// Create client cache object
 	cache := common.Cache[*Client]{
@@ -416,89 +645,34 @@ func (c *Client) Cache() common.Cache[*Client] {
 	return cache
 }
 
+func ForgetClient(w xproto.Window) {
+	Sessions.Lock.Lock()
+	defer Sessions.Lock.Unlock()
+
+	// Remove session state of closed window
+	delete(Sessions.Created, w)
+	delete(Sessions.Processes, w)
+}
+
 func (c *Client) IsNew() bool {
 	created := time.UnixMilli(c.Window.Created)
 	return time.Since(created) < 1000*time.Millisecond
 }
 
 func IsSpecial(info *Info) bool {
-
-	// Check internal windows
-	if info.Class == common.Build.Name {
-		log.Info("Ignore internal window [", info.Class, "]")
-		return true
-	}
-
-	// Check window types
-	types := []string{
-		"_NET_WM_WINDOW_TYPE_DOCK",
-		"_NET_WM_WINDOW_TYPE_DESKTOP",
-		"_NET_WM_WINDOW_TYPE_TOOLBAR",
-		"_NET_WM_WINDOW_TYPE_UTILITY",
-		"_NET_WM_WINDOW_TYPE_TOOLTIP",
-		"_NET_WM_WINDOW_TYPE_SPLASH",
-		"_NET_WM_WINDOW_TYPE_DIALOG",
-		"_NET_WM_WINDOW_TYPE_COMBO",
-		"_NET_WM_WINDOW_TYPE_NOTIFICATION",
-		"_NET_WM_WINDOW_TYPE_DROPDOWN_MENU",
-		"_NET_WM_WINDOW_TYPE_POPUP_MENU",
-		"_NET_WM_WINDOW_TYPE_MENU",
-		"_NET_WM_WINDOW_TYPE_DND",
-	}
-	for _, typ := range info.Types {
-		if common.IsInList(typ, types) {
-			log.Info("Ignore window with type ", typ, " [", info.Class, "]")
-			return true
-		}
-	}
-
-	// Check window states
-	states := []string{
-		"_NET_WM_STATE_HIDDEN",
-		"_NET_WM_STATE_MODAL",
-		"_NET_WM_STATE_ABOVE",
-		"_NET_WM_STATE_BELOW",
-		"_NET_WM_STATE_SKIP_PAGER",
-		"_NET_WM_STATE_SKIP_TASKBAR",
-	}
-	for _, state := range info.States {
-		if common.IsInList(state, states) {
-			log.Info("Ignore window with state ", state, " [", info.Class, "]")
-			return true
-		}
-	}
-
-	return false
+	return applied(SpecialRules(info), info)
 }
 
 func IsIgnored(info *Info) bool {
+	return applied(IgnoreRules(info), info)
+}
 
-	// Check invalid windows
-	if len(info.Class) == 0 {
-		log.Info("Ignore invalid window")
-		return true
-	}
-
-	// Check ignored windows
-	for _, s := range common.Config.WindowIgnore {
-		conf_class := s[0]
-		conf_name := s[1]
-
-		reg_class := regexp.MustCompile(strings.ToLower(conf_class))
-		reg_name := regexp.MustCompile(strings.ToLower(conf_name))
-
-		// Ignore all windows with this class
-		class_match := reg_class.MatchString(strings.ToLower(info.Class))
-
-		// But allow the window with a special name
-		name_match := conf_name != "" && reg_name.MatchString(strings.ToLower(info.Name))
-
-		if class_match && !name_match {
-			log.Info("Ignore window with ", strings.TrimSpace(strings.Join(s, " ")), " from config [", info.Class, "]")
+func IsGame(info *Info) bool {
+	for _, rule := range GameRules(info) {
+		if rule.Applied {
 			return true
 		}
 	}
-
 	return false
 }
 
@@ -518,38 +692,108 @@ func IsSticky(info *Info) bool {
 	return common.IsInList("_NET_WM_STATE_STICKY", info.States)
 }
 
+func IsWithdrawn(w xproto.Window) bool {
+
+	// Check destroyed windows
+	if _, err := xproto.GetWindowAttributes(X.Conn(), w).Reply(); err != nil {
+		return true
+	}
+
+	// Check withdrawn windows
+	state, err := icccm.WmStateGet(X, w)
+	return err == nil && state.State == icccm.StateWithdrawn
+}
+
 func GetInfo(w xproto.Window) *Info {
+	return GetInfoBatch([]xproto.Window{w})[w]
+}
+
+func GetInfoBatch(windows []xproto.Window) map[xproto.Window]*Info {
+	infos := make(map[xproto.Window]*Info, len(windows))
+
+	// Request window geometries and properties at once
+	cookies := make(map[xproto.Window]XGeometryCookies, len(windows))
+	for _, w := range windows {
+		cookies[w] = XGeometryCookies{
+			Geometry:  xproto.GetGeometry(X.Conn(), xproto.Drawable(w)),
+			Translate: xproto.TranslateCoordinates(X.Conn(), w, X.RootWin(), 0, 0),
+		}
+	}
+	replies := PropertiesGet(windows, infoAtoms)
+
+	// Create window infos from replies
+	if len(windows) < infoThreshold {
+		for _, w := range windows {
+			infos[w] = createInfo(w, replies[w], cookies[w])
+		}
+		return infos
+	}
+
+	// Create window infos within bounded worker pool
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan xproto.Window)
+	for i := 0; i < common.MinInt(infoWorkers, len(windows)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range jobs {
+				info := createInfo(w, replies[w], cookies[w])
+				lock.Lock()
+				infos[w] = info
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, w := range windows {
+		jobs <- w
+	}
+	close(jobs)
+	wg.Wait()
+
+	return infos
+}
+
+func createInfo(w xproto.Window, props XPropertyReplies, cookies XGeometryCookies) *Info {
 	var err error
 
 	var class string
 	var name string
+	var process Process
 	var types []string
 	var states []string
 	var location Location
 	var dimensions Dimensions
 
 	// Window class (internal class name of the window)
-	cls, err := icccm.WmClassGet(X, w)
+	cls, err := xprop.PropValStrs(props.Get("WM_CLASS"))
 	if err != nil {
 		log.Trace("Error on request: ", err)
-	} else if cls != nil {
-		class = cls.Class
+	} else if len(cls) == 2 {
+		class = cls[1]
 	}
 
 	// Window name (title on top of the window)
-	name, err = icccm.WmNameGet(X, w)
+	name, err = xprop.PropValStr(props.Get("WM_NAME"))
 	if err != nil {
 		name = class
 	}
 
+	// Window process (process id and executable of the window)
+	pid, err := xprop.PropValNum(props.Get("_NET_WM_PID"))
+	if err == nil {
+		machine, _ := xprop.PropValStr(props.Get("WM_CLIENT_MACHINE"))
+		process = clientProcess(w, pid, machine)
+	}
+
 	// Window geometry (dimensions of the window)
-	geom, err := CreateXWindow(w).Instance.DecorGeometry()
+	geom, err := decorGeometry(w, props, cookies)
 	if err != nil {
 		geom = &xrect.XRect{}
 	}
 
 	// Window desktop and screen (window workspace location)
-	desktop, err := ewmh.WmDesktopGet(X, w)
+	desktop, err := xprop.PropValNum(props.Get("_NET_WM_DESKTOP"))
 	sticky := desktop > Workplace.DesktopCount
 	if err != nil || sticky {
 		desktop = CurrentDesktopGet(X)
@@ -560,13 +804,13 @@ func GetInfo(w xproto.Window) *Info {
 	}
 
 	// Window types (types of the window)
-	types, err = ewmh.WmWindowTypeGet(X, w)
+	types, err = props.Atoms("_NET_WM_WINDOW_TYPE")
 	if err != nil {
 		types = []string{}
 	}
 
 	// Window states (states of the window)
-	states, err = ewmh.WmStateGet(X, w)
+	states, err = props.Atoms("_NET_WM_STATE")
 	if err != nil {
 		states = []string{}
 	}
@@ -575,20 +819,23 @@ func GetInfo(w xproto.Window) *Info {
 	}
 
 	// Window normal hints (normal hints of the window)
-	nhints, err := icccm.WmNormalHintsGet(X, w)
+	nhints, err := normalHints(xprop.PropValNums(props.Get("WM_NORMAL_HINTS")))
 	if err != nil {
 		nhints = &icccm.NormalHints{}
 	}
 
 	// Window motif hints (hints of the window)
-	mhints, err := motif.WmHintsGet(X, w)
+	mhints, err := motifHints(xprop.PropValNums(props.Get("_MOTIF_WM_HINTS")))
 	if err != nil {
 		mhints = &motif.Hints{}
 	}
 
 	// Window extents (server/client decorations of the window)
-	extNet, _ := xprop.PropValNums(xprop.GetProperty(X, w, "_NET_FRAME_EXTENTS"))
-	extGtk, _ := xprop.PropValNums(xprop.GetProperty(X, w, "_GTK_FRAME_EXTENTS"))
+	extNet, _ := xprop.PropValNums(props.Get("_NET_FRAME_EXTENTS"))
+	if !Capable("frame_extents") {
+		extNet = nil
+	}
+	extGtk, _ := xprop.PropValNums(props.Get("_GTK_FRAME_EXTENTS"))
 
 	ext := make([]uint, 4)
 	for i, e := range extNet {
@@ -616,12 +863,150 @@ func GetInfo(w xproto.Window) *Info {
 		AdjRestore: !common.AllZero(extGtk),
 	}
 
-	return &Info{
+	info := &Info{
 		Class:      class,
 		Name:       name,
+		Process:    process,
 		Types:      types,
 		States:     states,
 		Location:   location,
 		Dimensions: dimensions,
 	}
+
+	// Window quirks (known toolkit issues of the window)
+	applyQuirk(info)
+
+	return info
+}
+
+func (info *Info) Copy() *Info {
+	copied := *info
+	copied.Types = append([]string{}, info.Types...)
+	copied.States = append([]string{}, info.States...)
+	return &copied
+}
+
+func decorGeometry(w xproto.Window, props XPropertyReplies, cookies XGeometryCookies) (xrect.Rect, error) {
+	geom, err := cookies.Geometry.Reply()
+	if err != nil {
+		return nil, err
+	}
+	pos, err := cookies.Translate.Reply()
+	if err != nil {
+		return nil, err
+	}
+
+	// Query frame window of windows without frame extents
+	ext, err := xprop.PropValNums(props.Get("_NET_FRAME_EXTENTS"))
+	if err != nil || len(ext) != 4 {
+		return CreateXWindow(w).Instance.DecorGeometry()
+	}
+
+	// Add frame extents to root position of window
+	border := int(geom.BorderWidth)
+	return xrect.New(
+		int(pos.DstX)-border-int(ext[0]),
+		int(pos.DstY)-border-int(ext[2]),
+		int(geom.Width)+int(ext[0])+int(ext[1]),
+		int(geom.Height)+int(ext[2])+int(ext[3]),
+	), nil
+}
+
+func clientProcess(w xproto.Window, pid uint, machine string) Process {
+
+	// Skip processes of remote clients
+	if len(machine) > 0 && machine != common.Process.Host.Hostname {
+		return Process{}
+	}
+
+	// Reuse resolved process of window
+	Sessions.Lock.Lock()
+	process, ok := Sessions.Processes[w]
+	Sessions.Lock.Unlock()
+	if ok && process.Id == pid {
+		return process
+	}
+
+	// Resolve process of window
+	process = Process{
+		Id:   pid,
+		Name: processName(pid),
+	}
+
+	Sessions.Lock.Lock()
+	Sessions.Processes[w] = process
+	Sessions.Lock.Unlock()
+
+	return process
+}
+
+func processName(pid uint) string {
+
+	// Read executable path of process
+	exe, err := os.Readlink(filepath.Join("/proc", fmt.Sprint(pid), "exe"))
+	if err == nil {
+		return filepath.Base(strings.TrimSuffix(exe, " (deleted)"))
+	}
+
+	// Read command name of process
+	comm, err := os.ReadFile(filepath.Join("/proc", fmt.Sprint(pid), "comm"))
+	if err == nil {
+		return strings.TrimSpace(string(comm))
+	}
+
+	return ""
+}
+
+func normalHints(hints []uint, err error) (*icccm.NormalHints, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(hints) != 18 {
+		return nil, fmt.Errorf("invalid number of normal hints %d", len(hints))
+	}
+
+	// Parse normal hints
+	nh := &icccm.NormalHints{
+		Flags:        hints[0],
+		X:            int(hints[1]),
+		Y:            int(hints[2]),
+		Width:        hints[3],
+		Height:       hints[4],
+		MinWidth:     hints[5],
+		MinHeight:    hints[6],
+		MaxWidth:     hints[7],
+		MaxHeight:    hints[8],
+		WidthInc:     hints[9],
+		HeightInc:    hints[10],
+		MinAspectNum: hints[11],
+		MinAspectDen: hints[12],
+		MaxAspectNum: hints[13],
+		MaxAspectDen: hints[14],
+		BaseWidth:    hints[15],
+		BaseHeight:   hints[16],
+		WinGravity:   hints[17],
+	}
+	if nh.WinGravity <= 0 {
+		nh.WinGravity = xproto.GravityNorthWest
+	}
+
+	return nh, nil
+}
+
+func motifHints(hints []uint, err error) (*motif.Hints, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(hints) != 5 {
+		return nil, fmt.Errorf("invalid number of motif hints %d", len(hints))
+	}
+
+	// Parse motif hints
+	return &motif.Hints{
+		Flags:      hints[0],
+		Function:   hints[1],
+		Decoration: hints[2],
+		Input:      hints[3],
+		Status:     hints[4],
+	}, nil
 }
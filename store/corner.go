@@ -47,7 +47,17 @@ func CreateCorners(screens []XHead) []*Corner {
 		bl := CreateCorner("bottom_left", uint(i), x, y+h-hs, ws, hs)
 		cl := CreateCorner("center_left", uint(i), x, y+h/2-hl/2, ws, hl)
 
-		corners = append(corners, []*Corner{tl, tc, tr, cr, br, bc, bl, cl}...)
+		// Add top and bottom corners per virtual screen
+		corners = append(corners, []*Corner{tl, tc, tr, br, bc, bl}...)
+
+		// Skip center corners at inner boundaries of virtual screens, the pointer crosses them when changing screens
+		px, _, pw, _ := screen.Physical.Pieces()
+		if pw == 0 || x+w == px+pw {
+			corners = append(corners, cr)
+		}
+		if pw == 0 || x == px {
+			corners = append(corners, cl)
+		}
 	}
 
 	return corners
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"os"
+
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Corrections = &XCorrections{Offsets: map[string]*Correction{}, Candidates: map[string]common.Geometry{}} // Learned frame corrections
+)
+
+type XCorrections struct {
+	Offsets    map[string]*Correction     // Frame corrections per window class
+	Candidates map[string]common.Geometry // Observed offsets awaiting confirmation per window class
+}
+
+type Correction struct {
+	Offset     common.Geometry // Difference between resulting and requested geometry
+	Learned    bool            // Correction was learned from a completed move
+	Calibrated bool            // Correction was measured by calibration
+}
+
+var (
+	correctionMax = 64 // Maximum offset considered as decoration error
+)
+
+func InitCorrections() {
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Read calibrated corrections
+	data, err := os.ReadFile(CorrectionFilePath())
+	if err != nil {
+		return
+	}
+	offsets := map[string]common.Geometry{}
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		log.Warn("Error reading corrections file: ", err)
+		return
+	}
+	for class, offset := range offsets {
+		Corrections.Offsets[class] = &Correction{Offset: offset, Learned: true, Calibrated: true}
+	}
+
+	log.Info("Calibrated frame corrections ", offsets)
+}
+
+func CorrectionFilePath() string {
+	return filepath.Join(common.Args.Cache, "corrections.json")
+}
+
+func (c *Client) Correction() common.Geometry {
+
+	// Obtain learned correction of window class
+	correction, ok := Corrections.Offsets[c.Latest.Class]
+	if !ok || !correction.Learned {
+		return common.Geometry{}
+	}
+
+	// Calibrated corrections apply regardless of capabilities
+	if !correction.Calibrated && !Capable("frame_correction") {
+		return common.Geometry{}
+	}
+
+	return correction.Offset
+}
+
+func (c *Client) Calibrate() bool {
+	if len(c.Latest.Class) == 0 {
+		return false
+	}
+
+	// Calibration requires a tiled and settled window
+	target := c.Moved.Target
+	if target.Width <= 0 || target.Height <= 0 || c.Animating() {
+		log.Warn("Error calibrating frame correction, window is not tiled [", c.Latest.Class, "]")
+		return false
+	}
+
+	// Measure visible frame of window
+	visible, err := c.visibleGeometry()
+	if err != nil {
+		log.Warn("Error calibrating frame correction: ", err, " [", c.Latest.Class, "]")
+		return false
+	}
+
+	// Combine measured offset with currently applied correction
+	correction := c.Correction()
+	offset := common.Geometry{
+		X:      correction.X + visible.X - target.X,
+		Y:      correction.Y + visible.Y - target.Y,
+		Width:  correction.Width + visible.Width - target.Width,
+		Height: correction.Height + visible.Height - target.Height,
+	}
+	for _, value := range []int{offset.X, offset.Y, offset.Width, offset.Height} {
+		if common.AbsInt(value) > correctionMax {
+			log.Warn("Error calibrating frame correction, offset ", offset, " exceeds ", correctionMax, "px [", c.Latest.Class, "]")
+			return false
+		}
+	}
+
+	// Store calibrated correction
+	delete(Corrections.Candidates, c.Latest.Class)
+	Corrections.Offsets[c.Latest.Class] = &Correction{Offset: offset, Learned: true, Calibrated: true}
+	writeCorrections()
+
+	// Request corrected geometry on next move
+	c.Moved = Moved{}
+
+	log.Info("Calibrated frame correction ", offset, " [", c.Latest.Class, "]")
+
+	return true
+}
+
+func (c *Client) visibleGeometry() (common.Geometry, error) {
+
+	// Outer window dimensions (server side decorations)
+	oGeom, err := c.Window.Instance.DecorGeometry()
+	if err != nil {
+		return common.Geometry{}, err
+	}
+
+	// Client side decorations (invisible shadows around the window)
+	gtk, _ := xprop.PropValNums(xprop.GetProperty(X, c.Window.Id, "_GTK_FRAME_EXTENTS"))
+	if len(gtk) != 4 || common.AllZero(gtk) {
+		return *common.CreateGeometry(oGeom), nil
+	}
+
+	// Inner window dimensions (x/y relative to root window)
+	iGeom, err := xwindow.RawGeometry(X, xproto.Drawable(c.Window.Id))
+	if err != nil {
+		return common.Geometry{}, err
+	}
+	pos, err := xproto.TranslateCoordinates(X.Conn(), c.Window.Id, X.RootWin(), 0, 0).Reply()
+	if err != nil {
+		return common.Geometry{}, err
+	}
+
+	// Visible frame without client side shadows
+	return common.Geometry{
+		X:      int(pos.DstX) + int(gtk[0]),
+		Y:      int(pos.DstY) + int(gtk[2]),
+		Width:  iGeom.Width() - int(gtk[0]+gtk[1]),
+		Height: iGeom.Height() - int(gtk[2]+gtk[3]),
+	}, nil
+}
+
+func (c *Client) learn() {
+	if !Capable("frame_correction") || c.Moved.Target.Width <= 0 || c.Moved.Target.Height <= 0 || c.Animating() {
+		return
+	}
+	if correction, ok := Corrections.Offsets[c.Latest.Class]; ok && correction.Learned {
+		return
+	}
+
+	// Ignore results clamped by size hints
+	target, result := c.Moved.Target, c.Latest.Dimensions.Geometry
+	if c.clamped(target) {
+		return
+	}
+
+	// Ignore offsets not caused by decorations
+	offset := common.Geometry{
+		X:      result.X - target.X,
+		Y:      result.Y - target.Y,
+		Width:  result.Width - target.Width,
+		Height: result.Height - target.Height,
+	}
+	for _, value := range []int{offset.X, offset.Y, offset.Width, offset.Height} {
+		if common.AbsInt(value) > correctionMax {
+			return
+		}
+	}
+
+	// Require the same offset on two consecutive moves
+	if candidate, ok := Corrections.Candidates[c.Latest.Class]; !ok || candidate != offset {
+		Corrections.Candidates[c.Latest.Class] = offset
+		return
+	}
+	delete(Corrections.Candidates, c.Latest.Class)
+	Corrections.Offsets[c.Latest.Class] = &Correction{Offset: offset, Learned: true}
+
+	// Request corrected geometry on next move
+	if offset != (common.Geometry{}) {
+		log.Info("Learned frame correction ", offset, " [", c.Latest.Class, "]")
+		c.Moved = Moved{}
+	}
+}
+
+func (c *Client) clamped(target common.Geometry) bool {
+	hints := c.Latest.Dimensions.Hints.Normal
+
+	// Size increments round the requested size
+	if hints.WidthInc > 1 || hints.HeightInc > 1 {
+		return true
+	}
+
+	// Minimum and maximum sizes limit the requested size
+	if hints.Flags&icccm.SizeHintPMinSize > 0 && (int(hints.MinWidth) > target.Width || int(hints.MinHeight) > target.Height) {
+		return true
+	}
+	if hints.Flags&icccm.SizeHintPMaxSize > 0 && ((hints.MaxWidth > 0 && int(hints.MaxWidth) < target.Width) || (hints.MaxHeight > 0 && int(hints.MaxHeight) < target.Height)) {
+		return true
+	}
+
+	return false
+}
+
+func writeCorrections() {
+	if common.CacheDisabled() {
+		return
+	}
+	offsets := map[string]common.Geometry{}
+
+	// Collect calibrated corrections
+	for class, correction := range Corrections.Offsets {
+		if correction.Calibrated {
+			offsets[class] = correction.Offset
+		}
+	}
+
+	// Write calibrated corrections
+	data, err := json.MarshalIndent(offsets, "", "  ")
+	if err != nil {
+		return
+	}
+	path := CorrectionFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn("Error creating corrections folder: ", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn("Error writing corrections file: ", err)
+	}
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/xevent"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type XErrors struct {
+	Windows map[xproto.Window]time.Time // Windows with failed requests
+	Lock    sync.Mutex                  // Lock for concurrent access
+}
+
+var (
+	Errors = &XErrors{Windows: map[xproto.Window]time.Time{}} // Failed requests per window
+)
+
+var (
+	errorCallbacksFun []func(xproto.Window) // Error events callback functions
+)
+
+var (
+	errorExpiry = 60 * time.Second // Time period after which failed windows are forgotten
+)
+
+func InitErrors() {
+
+	// Handle errors of unchecked requests
+	xevent.ErrorHandlerSet(X, handleError)
+}
+
+func Guarded(w xproto.Window) bool {
+	Errors.Lock.Lock()
+	defer Errors.Lock.Unlock()
+
+	// Check windows with recently failed requests
+	t, ok := Errors.Windows[w]
+
+	return ok && time.Since(t) <= errorExpiry
+}
+
+func Guard(w xproto.Window) {
+	Errors.Lock.Lock()
+	defer Errors.Lock.Unlock()
+
+	// Skip further requests of window
+	Errors.Windows[w] = time.Now()
+}
+
+func Unguard(w xproto.Window) {
+	Errors.Lock.Lock()
+	defer Errors.Lock.Unlock()
+
+	// Forget window with failed requests
+	delete(Errors.Windows, w)
+}
+
+func OnErrorUpdate(fun func(xproto.Window)) {
+	errorCallbacksFun = append(errorCallbacksFun, fun)
+}
+
+func handleError(err xgb.Error) {
+	var w xproto.Window
+
+	// Obtain window of failed request
+	switch e := err.(type) {
+	case xproto.WindowError:
+		w = xproto.Window(e.BadValue)
+	case xproto.DrawableError:
+		w = xproto.Window(e.BadValue)
+	default:
+		log.Warn("Error on request: ", err)
+		return
+	}
+
+	Errors.Lock.Lock()
+	now := time.Now()
+
+	// Forget expired windows
+	for ew, t := range Errors.Windows {
+		if now.Sub(t) > errorExpiry {
+			delete(Errors.Windows, ew)
+		}
+	}
+
+	// Suppress repeated errors of the same window
+	_, known := Errors.Windows[w]
+	Errors.Windows[w] = now
+	Errors.Lock.Unlock()
+	if known {
+		log.Trace("Suppress error on request: ", err)
+		return
+	}
+
+	// Error callbacks
+	errorCallbacks(w, err)
+}
+
+func errorCallbacks(w xproto.Window, err xgb.Error) {
+	log.Debug("Error event ", err.Error(), " [", w, "]")
+
+	for _, fun := range errorCallbacksFun {
+		fun(w)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	eventsLock            sync.Mutex         // Lock for concurrent queue access
+	eventsCallbacksFun    []func(XEvents)    // Events metrics callback functions
+	eventsQueueProcessFun func(aname string) // Function to process queued events
+	eventsDispatchFun     func(func())       // Function to run processing on state goroutine
+)
+
+type XEvents struct {
+	Pending   []string    `json:"-"` // Queued root property events
+	Timer     *time.Timer `json:"-"` // Timer to process queued events
+	Received  int         // Number of received root property events
+	Processed int         // Number of processed root property events
+	Dropped   int         // Number of dropped duplicate events
+}
+
+func InitEvents(fun func(aname string)) {
+	Events = &XEvents{}
+	eventsQueueProcessFun = fun
+}
+
+func QueueEvent(aname string) {
+	delay := common.Config.TilingCoalesce
+	if delay <= 0 {
+		eventsLock.Lock()
+		Events.Received += 1
+		Events.Processed += 1
+		eventsLock.Unlock()
+
+		DispatchEvent(func() { eventsQueueProcessFun(aname) })
+		return
+	}
+
+	eventsLock.Lock()
+	defer eventsLock.Unlock()
+
+	// Drop duplicate events
+	Events.Received += 1
+	if common.IsInList(aname, Events.Pending) {
+		Events.Dropped += 1
+		return
+	}
+	Events.Pending = append(Events.Pending, aname)
+
+	// Process queue after coalescing window
+	if Events.Timer == nil {
+		Events.Timer = time.AfterFunc(time.Duration(delay)*time.Millisecond, func() {
+			DispatchEvent(processEvents)
+		})
+	}
+}
+
+func DispatchEvent(fun func()) {
+	if eventsDispatchFun == nil {
+		fun()
+		return
+	}
+
+	// Run on state goroutine instead of timer goroutine
+	eventsDispatchFun(fun)
+}
+
+func OnEventsUpdate(fun func(XEvents)) {
+	eventsCallbacksFun = append(eventsCallbacksFun, fun)
+}
+
+func OnEventsDispatch(fun func(func())) {
+	eventsDispatchFun = fun
+}
+
+func processEvents() {
+	eventsLock.Lock()
+	pending := Events.Pending
+	Events.Pending = nil
+	Events.Timer = nil
+	Events.Processed += len(pending)
+	metrics := *Events
+	eventsLock.Unlock()
+
+	log.Debug("Process ", len(pending), " queued events [", metrics.Dropped, "/", metrics.Received, " dropped]")
+
+	// Process queued events
+	for _, aname := range pending {
+		eventsQueueProcessFun(aname)
+	}
+
+	// Events callbacks
+	eventsCallbacks(metrics)
+}
+
+func eventsCallbacks(events XEvents) {
+	for _, fun := range eventsCallbacksFun {
+		fun(events)
+	}
+}
@@ -0,0 +1,40 @@
+package store
+
+type History struct {
+	Desktops []uint // Recently visited desktops (oldest first)
+	Maximum  int    // Maximum number of stored desktops
+}
+
+func CreateHistory(max int) *History {
+	return &History{
+		Desktops: make([]uint, 0),
+		Maximum:  max,
+	}
+}
+
+func (h *History) Push(desktop uint) {
+
+	// Ignore repeated desktops
+	if len(h.Desktops) > 0 && h.Desktops[len(h.Desktops)-1] == desktop {
+		return
+	}
+
+	// Append desktop and drop oldest entries
+	h.Desktops = append(h.Desktops, desktop)
+	if len(h.Desktops) > h.Maximum {
+		h.Desktops = h.Desktops[len(h.Desktops)-h.Maximum:]
+	}
+}
+
+func (h *History) Previous() (uint, bool) {
+
+	// Find most recent desktop that is not the current one
+	for i := len(h.Desktops) - 1; i >= 0; i-- {
+		desktop := h.Desktops[i]
+		if desktop != Workplace.CurrentDesktop && desktop < Workplace.DesktopCount {
+			return desktop, true
+		}
+	}
+
+	return 0, false
+}
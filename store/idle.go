@@ -0,0 +1,95 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	idleSupported    bool          // Screensaver extension is available
+	idleNext         time.Time     // Earliest time of next idle query
+	idleCallbacksFun []func(XIdle) // Idle events callback functions
+)
+
+type XIdle struct {
+	Active bool  // User is idle
+	Time   int64 // Time since last user input [ms]
+}
+
+func InitIdle(X *xgbutil.XUtil) {
+	Idle = &XIdle{}
+
+	// Init screensaver extension
+	err := screensaver.Init(X.Conn())
+	if err != nil {
+		log.Warn("Error initializing screensaver extension: ", err)
+		return
+	}
+	idleSupported = true
+}
+
+func IdleGet(X *xgbutil.XUtil) XIdle {
+	timeout := time.Duration(common.Config.PowerIdle) * time.Second
+	if !idleSupported || timeout <= 0 {
+		return XIdle{}
+	}
+
+	// Get time since last user input
+	info, err := screensaver.QueryInfo(X.Conn(), xproto.Drawable(X.RootWin())).Reply()
+	if err != nil {
+		log.Trace("Error retrieving idle time: ", err)
+		return XIdle{}
+	}
+	idle := time.Duration(info.MsSinceUserInput) * time.Millisecond
+
+	return XIdle{
+		Active: idle >= timeout,
+		Time:   idle.Milliseconds(),
+	}
+}
+
+func IdleUpdate(X *xgbutil.XUtil) *XIdle {
+	now := time.Now()
+	if now.Before(idleNext) {
+		return Idle
+	}
+	previous := *Idle
+
+	// Update current idle state
+	*Idle = IdleGet(X)
+
+	// Skip queries until the idle timeout may elapse (at least every second)
+	wait := time.Second
+	timeout := time.Duration(common.Config.PowerIdle) * time.Second
+	if remaining := timeout - time.Duration(Idle.Time)*time.Millisecond; !Idle.Active && remaining > wait {
+		wait = remaining
+	}
+	idleNext = now.Add(wait)
+
+	// Idle callbacks
+	if previous.Active != Idle.Active {
+		idleCallbacks(*Idle)
+	}
+
+	return Idle
+}
+
+func OnIdleUpdate(fun func(XIdle)) {
+	idleCallbacksFun = append(idleCallbacksFun, fun)
+}
+
+func idleCallbacks(idle XIdle) {
+	log.Info("Idle event ", idle.Active)
+
+	for _, fun := range idleCallbacksFun {
+		fun(idle)
+	}
+}
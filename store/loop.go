@@ -0,0 +1,78 @@
+package store
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/xevent"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	disconnectCallbacksFun []func()  // Connection loss callback functions
+	disconnectOnce         sync.Once // Run connection loss callbacks only once
+)
+
+type disconnectWriter struct {
+	io.Writer // Original xgb log writer
+}
+
+func (w disconnectWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+
+	// Detect connection loss before the event loop reads the closed connection
+	if strings.Contains(string(p), "read error is unrecoverable") {
+		disconnectOnce.Do(disconnectCallbacks)
+
+		// Block reader until process restarts or exits
+		select {}
+	}
+
+	return n, err
+}
+
+func Loop() {
+
+	// Hook into xgb reader errors
+	xgb.Logger.SetOutput(disconnectWriter{xgb.Logger.Writer()})
+
+	// Run X event loop
+	xevent.Main(X)
+}
+
+func Reconnectable() {
+	for {
+		time.Sleep(2000 * time.Millisecond)
+
+		// Probe X server and window manager on configured display
+		xu, err := xgbutil.NewConnDisplay(display)
+		if err != nil {
+			continue
+		}
+		_, err = ewmh.GetEwmhWM(xu)
+		xu.Conn().Close()
+		if err == nil {
+			log.Info("Connection to X server available")
+			return
+		}
+	}
+}
+
+func OnDisconnect(fun func()) {
+	disconnectCallbacksFun = append(disconnectCallbacksFun, fun)
+}
+
+func disconnectCallbacks() {
+	log.Warn("Connection to X server lost")
+
+	for _, fun := range disconnectCallbacksFun {
+		fun()
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"math"
 
+	"encoding/json"
+
 	"github.com/leukipp/cortile/v2/common"
 
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	snapCallbacksFun []func(float64, uint, uint) // Snap events callback functions
+)
+
 type Manager struct {
 	Name        string       // Manager name with window clients
 	Location    *Location    // Manager workspace and screen location
@@ -16,6 +22,8 @@ type Manager struct {
 	Masters     *Clients     // List of master window clients
 	Slaves      *Clients     // List of slave window clients
 	Decoration  bool         // Window decoration is enabled
+	Snapped     float64      `json:"-"` // Latest snapped master-slave proportion
+	Promoted    *Client      `json:"-"` // Client temporarily promoted into master area
 }
 
 type Location struct {
@@ -30,8 +38,10 @@ type Proportions struct {
 }
 
 type Clients struct {
-	Maximum int       // Currently maximum allowed clients
-	Stacked []*Client `json:"-"` // List of stored window clients
+	Maximum  int       // Currently maximum allowed clients
+	Extended int       `json:"-"` // Temporary increase of maximum by promotion
+	Fitted   int       `json:"-"` // Maximum visible clients of latest tiling (0 = maximum)
+	Stacked  []*Client `json:"-"` // List of stored window clients
 }
 
 type Directions struct {
@@ -68,6 +78,16 @@ func CreateManager(loc Location) *Manager {
 	}
 }
 
+func (cs *Clients) MarshalJSON() ([]byte, error) {
+	type clients Clients
+	c := clients(*cs)
+
+	// Persist maximum without temporary promotion
+	c.Maximum = common.MaxInt(c.Maximum-c.Extended, 0)
+
+	return json.Marshal(c)
+}
+
 func (mg *Manager) EnableDecoration() {
 	mg.Decoration = true
 }
@@ -106,7 +126,7 @@ func (mg *Manager) RemoveClient(c *Client) {
 	mi := mg.Index(mg.Masters, c)
 	if mi >= 0 {
 		if len(mg.Slaves.Stacked) > 0 {
-			mg.SwapClient(mg.Masters.Stacked[mi], mg.Slaves.Stacked[0])
+			mg.Masters.Stacked[mi] = mg.Slaves.Stacked[0]
 			mg.Slaves.Stacked = mg.Slaves.Stacked[1:]
 		} else {
 			mg.Masters.Stacked = removeClient(mg.Masters.Stacked, mi)
@@ -118,6 +138,8 @@ func (mg *Manager) RemoveClient(c *Client) {
 	if si >= 0 {
 		mg.Slaves.Stacked = removeClient(mg.Slaves.Stacked, si)
 	}
+
+	mg.unpromote()
 }
 
 func (mg *Manager) MakeMaster(c *Client) {
@@ -129,6 +151,140 @@ func (mg *Manager) MakeMaster(c *Client) {
 	}
 }
 
+func (mg *Manager) SwapMaster(c *Client) bool {
+	if len(mg.Masters.Stacked) == 0 {
+		return false
+	}
+
+	// Swap first master with first slave
+	if c == mg.Masters.Stacked[0] {
+		if len(mg.Slaves.Stacked) == 0 {
+			return false
+		}
+		c = mg.Slaves.Stacked[0]
+	}
+	mg.SwapClient(c, mg.Masters.Stacked[0])
+
+	return true
+}
+
+func (mg *Manager) CycleClients() bool {
+	clients := mg.Clients(Stacked)
+	if len(clients) < 2 {
+		return false
+	}
+
+	log.Info("Cycle clients [", mg.Name, "]")
+
+	// Rotate clients by one position
+	clients = append([]*Client{clients[len(clients)-1]}, clients[:len(clients)-1]...)
+	msize := len(mg.Masters.Stacked)
+	mg.Masters.Stacked = append(make([]*Client, 0, msize), clients[:msize]...)
+	mg.Slaves.Stacked = append(make([]*Client, 0, len(clients)-msize), clients[msize:]...)
+
+	mg.unpromote()
+
+	return true
+}
+
+func (mg *Manager) PromoteClient(c *Client) bool {
+	si := mg.Index(mg.Slaves, c)
+	if si < 0 {
+		return false
+	}
+
+	// Increase master area temporarily if full
+	if len(mg.Masters.Stacked) >= mg.Masters.Maximum {
+		if mg.Masters.Maximum >= common.Config.WindowMastersMax {
+			return false
+		}
+		mg.Masters.Maximum += 1
+		mg.Promoted, mg.Masters.Extended = c, 1
+	}
+
+	log.Info("Promote window to master [", c.Latest.Class, ", ", mg.Name, "]")
+
+	// Move slave to begin of master area
+	mg.Slaves.Stacked = removeClient(mg.Slaves.Stacked, si)
+	mg.Masters.Stacked = addClient(mg.Masters.Stacked, c)
+
+	return true
+}
+
+func (mg *Manager) DemoteClient(c *Client) bool {
+	mi := mg.Index(mg.Masters, c)
+	if mi < 0 {
+		return false
+	}
+
+	// Decrease master area
+	mg.Masters.Maximum = common.MaxInt(mg.Masters.Maximum-1, 0)
+	mg.Promoted, mg.Masters.Extended = nil, 0
+
+	log.Info("Demote window to slave [", c.Latest.Class, ", ", mg.Name, "]")
+
+	// Move master to begin of slave area
+	mg.Masters.Stacked = removeClient(mg.Masters.Stacked, mi)
+	mg.Slaves.Stacked = append([]*Client{c}, mg.Slaves.Stacked...)
+
+	return true
+}
+
+func (mg *Manager) InsertClient(c1 *Client, c2 *Client, after bool) bool {
+	if c1 == c2 || mg.Index(mg.Masters, c2) < 0 && mg.Index(mg.Slaves, c2) < 0 {
+		return false
+	}
+
+	log.Info("Insert client [", c1.Latest.Class, "-", c2.Latest.Class, ", ", mg.Name, "]")
+
+	// Remove client from stack
+	clients := []*Client{}
+	for _, c := range mg.Clients(Stacked) {
+		if c != c1 {
+			clients = append(clients, c)
+		}
+	}
+	if len(clients) == len(mg.Clients(Stacked)) {
+		return false
+	}
+
+	// Insert client before or after target
+	i := 0
+	for i < len(clients) && clients[i] != c2 {
+		i++
+	}
+	if after {
+		i++
+	}
+	clients = append(clients[:i], append([]*Client{c1}, clients[i:]...)...)
+
+	// Shift clients along master and slave area
+	msize := len(mg.Masters.Stacked)
+	mg.Masters.Stacked = append(make([]*Client, 0, msize), clients[:msize]...)
+	mg.Slaves.Stacked = append(make([]*Client, 0, len(clients)-msize), clients[msize:]...)
+
+	mg.unpromote()
+
+	return true
+}
+
+func (mg *Manager) ShiftClient(c *Client, offset int) bool {
+	clients := mg.Clients(Stacked)
+
+	// Obtain target position in stack
+	i := 0
+	for i < len(clients) && clients[i] != c {
+		i++
+	}
+	j := i + offset
+	if i >= len(clients) || j < 0 || j >= len(clients) || j == i {
+		return false
+	}
+
+	// Move client before or after target
+	return mg.InsertClient(c, clients[j], offset > 0)
+}
+
 func (mg *Manager) SwapClient(c1 *Client, c2 *Client) {
 	log.Info("Swap clients [", c1.Latest.Class, "-", c2.Latest.Class, ", ", mg.Name, "]")
 
@@ -147,12 +303,14 @@ func (mg *Manager) SwapClient(c1 *Client, c2 *Client) {
 	// Swap master with slave
 	if mIndex1 >= 0 && sIndex2 >= 0 {
 		mg.Slaves.Stacked[sIndex2], mg.Masters.Stacked[mIndex1] = mg.Masters.Stacked[mIndex1], mg.Slaves.Stacked[sIndex2]
+		mg.unpromote()
 		return
 	}
 
 	// Swap slave with master
 	if sIndex1 >= 0 && mIndex2 >= 0 {
 		mg.Masters.Stacked[mIndex2], mg.Slaves.Stacked[sIndex1] = mg.Slaves.Stacked[sIndex1], mg.Masters.Stacked[mIndex2]
+		mg.unpromote()
 		return
 	}
 
@@ -227,6 +385,7 @@ func (mg *Manager) PreviousClient() *Client {
 func (mg *Manager) IncreaseMaster() {
 
 	// Increase master area
+	mg.Promoted, mg.Masters.Extended = nil, 0
 	if len(mg.Slaves.Stacked) > 1 && mg.Masters.Maximum < common.Config.WindowMastersMax {
 		mg.Masters.Maximum += 1
 		mg.Masters.Stacked = append(mg.Masters.Stacked, mg.Slaves.Stacked[0])
@@ -239,6 +398,7 @@ func (mg *Manager) IncreaseMaster() {
 func (mg *Manager) DecreaseMaster() {
 
 	// Decrease master area
+	mg.Promoted, mg.Masters.Extended = nil, 0
 	if len(mg.Masters.Stacked) > 0 {
 		mg.Masters.Maximum -= 1
 		mg.Slaves.Stacked = append([]*Client{mg.Masters.Stacked[len(mg.Masters.Stacked)-1]}, mg.Slaves.Stacked...)
@@ -284,6 +444,63 @@ func (mg *Manager) DecreaseProportion() {
 	mg.SetProportions(mg.Proportions.MasterSlave[2], proportion, 0, 1)
 }
 
+func (mg *Manager) IncreaseClientProportion(c *Client) bool {
+	return mg.changeClientProportion(c, common.Config.ProportionStep)
+}
+
+func (mg *Manager) DecreaseClientProportion(c *Client) bool {
+	return mg.changeClientProportion(c, -common.Config.ProportionStep)
+}
+
+func (mg *Manager) changeClientProportion(c *Client, step float64) bool {
+
+	// Obtain column of client
+	windows, proportions := mg.Masters, mg.Proportions.MasterMaster
+	if !mg.IsMaster(c) {
+		windows, proportions = mg.Slaves, mg.Proportions.SlaveSlave
+	}
+	size := common.MinInt(len(windows.Stacked), windows.Maximum)
+	index := mg.Index(windows, c)
+	if size <= 1 || index < 0 {
+		return false
+	}
+
+	// Change client proportion within its column, the next neighbor compensates
+	i := index % size
+	j := i + 1
+	if j >= size {
+		j = i - 1
+	}
+	precision := 1.0 / common.Config.ProportionStep
+	proportion := math.Round(proportions[size][i]*precision)/precision + step
+
+	return mg.SetProportions(proportions[size], proportion, i, j)
+}
+
+func (mg *Manager) SetProportion(proportion float64) bool {
+
+	// Set root proportion
+	return mg.SetProportions(mg.Proportions.MasterSlave[2], proportion, 0, 1)
+}
+
+func (mg *Manager) EqualizeProportions() {
+
+	// Distribute master-slave, master and slave proportions evenly
+	mg.Proportions.MasterSlave = calcProportions(2)
+	mg.Proportions.MasterMaster = calcProportions(common.Config.WindowMastersMax)
+	mg.Proportions.SlaveSlave = calcProportions(common.Config.WindowSlavesMax)
+
+	log.Info("Equalize proportions [", mg.Name, "]")
+}
+
+func (mg *Manager) ResetProportions() {
+
+	// Reset all proportions to defaults
+	mg.EqualizeProportions()
+
+	log.Info("Reset proportions [", mg.Name, "]")
+}
+
 func (mg *Manager) SetProportions(ps []float64, pi float64, i int, j int) bool {
 
 	// Ignore changes on border sides
@@ -311,6 +528,34 @@ func (mg *Manager) SetProportions(ps []float64, pi float64, i int, j int) bool {
 	return true
 }
 
+func (mg *Manager) SnapProportion(p float64) float64 {
+	if common.Config.ProportionSnap <= 0 {
+		return p
+	}
+
+	// Find nearest stop within tolerance
+	snapped, distance := p, common.Config.ProportionSnap
+	for _, stop := range common.Config.ProportionStops {
+		for _, s := range []float64{stop, 1.0 - stop} {
+			if d := math.Abs(p - s); d <= distance {
+				snapped, distance = s, d
+			}
+		}
+	}
+
+	// Notify about changed stops
+	if snapped == p {
+		mg.Snapped = 0
+		return p
+	}
+	if snapped != mg.Snapped {
+		mg.Snapped = snapped
+		snapCallbacks(snapped, mg.Location.Desktop, mg.Location.Screen)
+	}
+
+	return snapped
+}
+
 func (mg *Manager) IsMaster(c *Client) bool {
 
 	// Check if window is master
@@ -351,12 +596,73 @@ func (mg *Manager) Ordered(windows *Clients) []*Client {
 	return ordered
 }
 
+func (mg *Manager) Geometry() *common.Geometry {
+	dim := DesktopGeometry(mg.Location.Screen)
+
+	// Limit content width while only a few clients are tiled
+	csize := len(mg.Masters.Stacked) + len(mg.Slaves.Stacked)
+	if common.Config.EdgeMaxWidth <= 0 || csize == 0 || csize > common.Config.EdgeMaxWidthClients {
+		return dim
+	}
+	width := int(math.Round(float64(common.Config.EdgeMaxWidth) * ScreenScale(mg.Location.Screen)))
+	if dim.Width <= width {
+		return dim
+	}
+
+	return &common.Geometry{
+		X:      dim.X + (dim.Width-width)/2,
+		Y:      dim.Y,
+		Width:  width,
+		Height: dim.Height,
+	}
+}
+
+func (mg *Manager) Fit(windows *Clients, space int, gap int, horizontal bool) int {
+	windows.Fitted = 0
+	size := common.MinInt(len(windows.Stacked), windows.Maximum)
+	if len(common.Config.WindowMinSize) == 0 || size <= 1 {
+		return windows.Maximum
+	}
+
+	// Obtain largest minimum tile size along the stacking direction
+	minimum := 0
+	for _, c := range windows.Stacked {
+		w, h := MinSizeGet(c.Latest)
+		if horizontal {
+			minimum = common.MaxInt(minimum, w)
+		} else {
+			minimum = common.MaxInt(minimum, h)
+		}
+	}
+	minimum = int(math.Round(float64(minimum) * ScreenScale(mg.Location.Screen)))
+	if minimum == 0 {
+		return windows.Maximum
+	}
+
+	// Reduce visible tiles until each tile fits, overflow clients are stacked into hidden slots
+	fitted := size
+	for fitted > 1 && (space-(fitted+1)*gap)/fitted < minimum {
+		fitted -= 1
+	}
+	if fitted == size {
+		return windows.Maximum
+	}
+	log.Debug("Fit ", fitted, "/", size, " tiles with minimum size ", minimum, " [", mg.Name, "]")
+	windows.Fitted = fitted
+
+	return fitted
+}
+
 func (mg *Manager) Visible(windows *Clients) []*Client {
-	visible := make([]*Client, common.MinInt(len(windows.Stacked), windows.Maximum))
+	maximum := windows.Maximum
+	if windows.Fitted > 0 && windows.Fitted < maximum {
+		maximum = windows.Fitted
+	}
+	visible := make([]*Client, common.MinInt(len(windows.Stacked), maximum))
 
 	// Create visible client list
 	for _, c := range mg.Ordered(windows) {
-		visible[mg.Index(windows, c)%windows.Maximum] = c
+		visible[mg.Index(windows, c)%maximum] = c
 	}
 
 	return visible
@@ -374,6 +680,35 @@ func (mg *Manager) Clients(flag uint8) []*Client {
 	return make([]*Client, 0)
 }
 
+func OnSnapUpdate(fun func(float64, uint, uint)) {
+	snapCallbacksFun = append(snapCallbacksFun, fun)
+}
+
+func snapCallbacks(proportion float64, desktop uint, screen uint) {
+	log.Debug("Snap event ", proportion)
+
+	for _, fun := range snapCallbacksFun {
+		fun(proportion, desktop, screen)
+	}
+}
+
+func (mg *Manager) unpromote() {
+	if mg.Promoted == nil || mg.Index(mg.Masters, mg.Promoted) >= 0 {
+		return
+	}
+
+	log.Info("Restore master area of promoted window [", mg.Promoted.Latest.Class, ", ", mg.Name, "]")
+
+	// Shrink master area increased by promotion
+	mg.Promoted, mg.Masters.Extended = nil, 0
+	mg.Masters.Maximum = common.MaxInt(mg.Masters.Maximum-1, 0)
+	for len(mg.Masters.Stacked) > mg.Masters.Maximum {
+		last := mg.Masters.Stacked[len(mg.Masters.Stacked)-1]
+		mg.Masters.Stacked = mg.Masters.Stacked[:len(mg.Masters.Stacked)-1]
+		mg.Slaves.Stacked = append([]*Client{last}, mg.Slaves.Stacked...)
+	}
+}
+
 func addClient(cs []*Client, c *Client) []*Client {
 	return append([]*Client{c}, cs...)
 }
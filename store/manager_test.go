@@ -0,0 +1,115 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"encoding/json"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func TestInsertClient(t *testing.T) {
+	mg, clients := createTestManager(4)
+
+	// Insert slave before master
+	if !mg.InsertClient(clients[1], clients[0], false) {
+		t.Fatal("insert failed")
+	}
+	if got := testClasses(mg.Clients(Stacked)); got != "[test-1 test-0 test-3 test-2]" {
+		t.Fatal("unexpected stack ", got)
+	}
+	if !mg.IsMaster(clients[1]) || mg.IsMaster(clients[0]) {
+		t.Fatal("unexpected master ", testClasses(mg.Masters.Stacked))
+	}
+
+	// Shift client back to the end
+	if !mg.ShiftClient(clients[1], 3) {
+		t.Fatal("shift failed")
+	}
+	if got := testClasses(mg.Clients(Stacked)); got != "[test-0 test-3 test-2 test-1]" {
+		t.Fatal("unexpected stack ", got)
+	}
+
+	// Reject insertion of a client onto itself
+	if mg.InsertClient(clients[1], clients[1], true) {
+		t.Fatal("self insert succeeded")
+	}
+}
+
+func TestSetProportions(t *testing.T) {
+	mg, _ := createTestManager(0)
+	ps := mg.Proportions.MasterSlave[2]
+
+	// Move border between master and slave area
+	if !mg.SetProportions(ps, 0.7, 0, 1) {
+		t.Fatal("proportion rejected")
+	}
+	if math.Abs(ps[0]-0.7) > 1e-9 || math.Abs(ps[0]+ps[1]-1.0) > 1e-9 {
+		t.Fatal("unexpected proportions ", ps)
+	}
+
+	// Reject proportions below minimum
+	if mg.SetProportions(ps, common.Config.ProportionMin/2, 0, 1) {
+		t.Fatal("proportion below minimum accepted")
+	}
+	if mg.SetProportions(ps, 0.5, 0, 0) {
+		t.Fatal("proportion on border side accepted")
+	}
+}
+
+func createTestManager(n int) (*Manager, []*Client) {
+	common.Config.WindowMastersMax = 2
+	common.Config.WindowSlavesMax = 4
+	common.Config.ProportionMin = 0.1
+	common.Config.ProportionStep = 0.1
+
+	// Create manager with clients
+	mg := CreateManager(Location{})
+	clients := make([]*Client, n)
+	for i := range clients {
+		clients[i] = &Client{
+			Window: &XWindow{Id: xproto.Window(i + 1)},
+			Latest: &Info{Class: fmt.Sprintf("test-%d", i)},
+		}
+		mg.AddClient(clients[i])
+	}
+
+	return mg, clients
+}
+
+func testClasses(clients []*Client) string {
+	classes := []string{}
+	for _, c := range clients {
+		classes = append(classes, c.Latest.Class)
+	}
+	return fmt.Sprint(classes)
+}
+
+func TestPromoteClientCache(t *testing.T) {
+	mg, clients := createTestManager(3)
+
+	// Promote slave into full master area
+	if !mg.PromoteClient(clients[1]) {
+		t.Fatal("promote failed")
+	}
+	if mg.Masters.Maximum != 2 {
+		t.Fatal("unexpected maximum ", mg.Masters.Maximum)
+	}
+
+	// Persist master maximum without promotion
+	data, err := json.Marshal(mg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := &Manager{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		t.Fatal(err)
+	}
+	if cached.Masters.Maximum != 1 {
+		t.Fatal("unexpected cached maximum ", cached.Masters.Maximum)
+	}
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MoveResizeSizeKeyboard uint32 = 9  // Keyboard initiated resize direction
+	MoveResizeMoveKeyboard uint32 = 10 // Keyboard initiated move direction
+	MoveResizeCancel       uint32 = 11 // Cancelled move/resize direction
+)
+
+var (
+	moveResizeCallbacksFun []func(xproto.Window, uint32) // Move/resize events callback functions
+)
+
+func InitMoveResize() {
+
+	// Attach root client messages
+	xevent.ClientMessageFun(func(X *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
+		aname, err := xprop.AtomName(X, ev.Type)
+		if err != nil || aname != "_NET_WM_MOVERESIZE" || len(ev.Data.Data32) < 3 {
+			return
+		}
+
+		// Move/resize callbacks
+		moveResizeCallbacks(ev.Window, ev.Data.Data32[2])
+	}).Connect(X, X.RootWin())
+}
+
+func OnMoveResizeUpdate(fun func(xproto.Window, uint32)) {
+	moveResizeCallbacksFun = append(moveResizeCallbacksFun, fun)
+}
+
+func moveResizeCallbacks(w xproto.Window, direction uint32) {
+	log.Debug("Move/resize event ", direction, " [", w, "]")
+
+	for _, fun := range moveResizeCallbacksFun {
+		fun(w, direction)
+	}
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	moves *XMoves // Pending batch of window moves
+)
+
+type XMoves struct {
+	Clients []*Client // Clients moved within batch
+}
+
+type Moved struct {
+	Target common.Geometry // Latest requested window geometry
+	Result common.Geometry // Window geometry observed after latest request
+}
+
+func BeginMoves() {
+	moves = &XMoves{}
+}
+
+func CommitMoves() {
+	if moves == nil {
+		return
+	}
+	clients := moves.Clients
+	moves = nil
+	if len(clients) == 0 {
+		return
+	}
+
+	// Wait until all requests are processed
+	X.Sync()
+
+	// Update stored dimensions at once
+	windows := make([]xproto.Window, len(clients))
+	for i, c := range clients {
+		windows[i] = c.Window.Id
+	}
+	infos := GetInfoBatch(windows)
+	for _, c := range clients {
+		if info := infos[c.Window.Id]; len(info.Class) > 0 {
+			c.Latest = info
+			c.learn()
+			c.observed()
+		}
+	}
+
+	log.Debug("Commit ", len(clients), " window moves")
+}
+
+func (c *Client) moved(target common.Geometry) bool {
+	if common.Args.DryRun {
+		return false
+	}
+	latest := c.Latest.Dimensions.Geometry
+
+	// Skip redundant requests
+	if c.Moved.Target == target && c.Moved.Result == latest {
+		log.Trace("Skip redundant window move/resize [", c.Latest.Class, "]")
+		return true
+	}
+	c.Moved = Moved{Target: target}
+
+	return false
+}
+
+func (c *Client) observed() {
+
+	// Remember geometry resulting from latest request
+	if !c.Animating() {
+		c.Moved.Result = c.Latest.Dimensions.Geometry
+	}
+}
+
+func (c *Client) applied() {
+	if moves == nil {
+		c.Update()
+		c.observed()
+		return
+	}
+
+	// Defer update until batch is committed
+	moves.Clients = append(moves.Clients, c)
+}
+
+func (c *Client) simulated(request string, values ...int) bool {
+
+	// Skip requests of windows with failed requests
+	if Guarded(c.Window.Id) {
+		log.Trace("Skip guarded ", request, " ", values, " [", c.Latest.Class, "]")
+		return true
+	}
+	if !common.Args.DryRun {
+		return false
+	}
+
+	// Log request instead of execution
+	log.Info("Dry-run ", request, " ", values, " [", c.Latest.Class, "]")
+
+	return true
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type XProperties struct {
+	Values map[xproto.Window]map[string]*xproto.GetPropertyReply // Cached property replies per window and atom
+	Lock   sync.RWMutex                                          // Lock for concurrent access
+}
+
+type XPropertyReply struct {
+	Reply *xproto.GetPropertyReply // Property reply from server
+	Err   error                    // Property request error
+}
+
+type XPropertyReplies map[string]XPropertyReply // Property replies per atom
+
+var (
+	infoAtoms = []string{
+		"WM_CLASS",
+		"WM_NAME",
+		"WM_NORMAL_HINTS",
+		"_MOTIF_WM_HINTS",
+		"_NET_WM_PID",
+		"WM_CLIENT_MACHINE",
+		"_NET_WM_DESKTOP",
+		"_NET_WM_WINDOW_TYPE",
+		"_NET_WM_STATE",
+		"_NET_FRAME_EXTENTS",
+		"_GTK_FRAME_EXTENTS",
+	} // Properties requested for window infos
+)
+
+var (
+	propertyCallbacksFun []func(xproto.Window, string) // Property events callback functions
+)
+
+var (
+	Properties = &XProperties{Values: map[xproto.Window]map[string]*xproto.GetPropertyReply{}} // Cached window properties
+)
+
+func WatchProperties(w xproto.Window) {
+	Properties.Lock.Lock()
+	_, watched := Properties.Values[w]
+	if !watched {
+		Properties.Values[w] = map[string]*xproto.GetPropertyReply{}
+	}
+	Properties.Lock.Unlock()
+	if watched {
+		return
+	}
+
+	// Invalidate cached properties on change
+	xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
+		aname, err := xprop.AtomName(X, ev.Atom)
+		if err != nil {
+			UnwatchProperties(w)
+			return
+		}
+		Properties.Lock.Lock()
+		if values, ok := Properties.Values[w]; ok {
+			delete(values, aname)
+		}
+		Properties.Lock.Unlock()
+
+		// Property callbacks
+		propertyCallbacks(w, aname)
+	}).Connect(X, w)
+}
+
+func UnwatchProperties(w xproto.Window) {
+	Properties.Lock.Lock()
+	defer Properties.Lock.Unlock()
+
+	// Remove cached properties
+	delete(Properties.Values, w)
+}
+
+func PropertyGet(w xproto.Window, atom string) (*xproto.GetPropertyReply, error) {
+	return PropertiesGet([]xproto.Window{w}, []string{atom})[w].Get(atom)
+}
+
+func PropertiesGet(windows []xproto.Window, atoms []string) map[xproto.Window]XPropertyReplies {
+	replies := make(map[xproto.Window]XPropertyReplies, len(windows))
+	cookies := make(map[xproto.Window]map[string]xproto.GetPropertyCookie, len(windows))
+
+	// Send requests for uncached properties
+	Properties.Lock.RLock()
+	for _, w := range windows {
+		replies[w] = make(XPropertyReplies, len(atoms))
+		cookies[w] = make(map[string]xproto.GetPropertyCookie)
+		for _, atom := range atoms {
+			if reply, ok := Properties.Values[w][atom]; ok {
+				replies[w][atom] = XPropertyReply{Reply: reply}
+				continue
+			}
+			aid, err := xprop.Atm(X, atom)
+			if err != nil {
+				replies[w][atom] = XPropertyReply{Err: err}
+				continue
+			}
+			cookies[w][atom] = xproto.GetProperty(X.Conn(), false, w, aid, xproto.GetPropertyTypeAny, 0, (1<<32)-1)
+		}
+	}
+	Properties.Lock.RUnlock()
+
+	// Collect replies of sent requests
+	for _, w := range windows {
+		for atom, cookie := range cookies[w] {
+			reply, err := cookie.Reply()
+			if err != nil {
+				replies[w][atom] = XPropertyReply{Err: fmt.Errorf("error retrieving property %s on window %x: %s", atom, w, err)}
+				continue
+			}
+			replies[w][atom] = XPropertyReply{Reply: reply}
+
+			// Cache property of watched windows
+			Properties.Lock.Lock()
+			if values, ok := Properties.Values[w]; ok {
+				values[atom] = reply
+			}
+			Properties.Lock.Unlock()
+		}
+	}
+
+	return replies
+}
+
+func (r XPropertyReplies) Get(atom string) (*xproto.GetPropertyReply, error) {
+	result, ok := r[atom]
+	if !ok {
+		return nil, fmt.Errorf("property %s not requested", atom)
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	// Validate property format
+	if result.Reply == nil || result.Reply.Format == 0 {
+		return nil, fmt.Errorf("no such property %s", atom)
+	}
+
+	return result.Reply, nil
+}
+
+func (r XPropertyReplies) Atoms(atom string) ([]string, error) {
+	reply, err := r.Get(atom)
+	return xprop.PropValAtoms(X, reply, err)
+}
+
+func OnPropertyUpdate(fun func(xproto.Window, string)) {
+	propertyCallbacksFun = append(propertyCallbacksFun, fun)
+}
+
+func propertyCallbacks(w xproto.Window, atom string) {
+	log.Trace("Property event ", atom, " [", w, "]")
+
+	for _, fun := range propertyCallbacksFun {
+		fun(w, atom)
+	}
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgbutil/ewmh"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Quirk struct {
+	Class   string        // Regex of window class, process executable (exe:) or process id (pid:)
+	Extents bool          // Disable frame extent adjustments
+	Settle  time.Duration // Delay tracking of new windows
+	Float   bool          // Never tile windows
+}
+
+func QuirkGet(info *Info) Quirk {
+	if len(info.Class) == 0 {
+		return Quirk{}
+	}
+
+	// Check configured quirks
+	for _, s := range common.Config.WindowQuirks {
+		if len(s) < 2 {
+			continue
+		}
+		conf_class := s[0]
+		conf_quirks := s[1]
+
+		if !matchClass(conf_class, info) {
+			continue
+		}
+
+		return parseQuirk(conf_class, conf_quirks, info)
+	}
+
+	return Quirk{}
+}
+
+func parseQuirk(class string, value string, info *Info) Quirk {
+	quirk := Quirk{Class: class}
+
+	// Parse comma separated quirks
+	for _, entry := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(strings.ToLower(entry)), "=")
+		switch name {
+		case "", "none":
+		case "extents":
+			quirk.Extents = true
+		case "float":
+			quirk.Float = true
+		case "settle":
+			delay, err := strconv.Atoi(arg)
+			if err != nil || delay < 0 {
+				log.Warn("Invalid quirk settle delay ", arg, " [", info.Class, "]")
+				continue
+			}
+			quirk.Settle = time.Duration(delay) * time.Millisecond
+		default:
+			log.Warn("Invalid quirk ", name, " [", info.Class, "]")
+		}
+	}
+
+	return quirk
+}
+
+func applyQuirk(info *Info) {
+	if !QuirkGet(info).Extents {
+		return
+	}
+
+	// Disable frame extent adjustments
+	info.Dimensions.Extents = ewmh.FrameExtents{}
+	info.Dimensions.AdjPos = false
+	info.Dimensions.AdjSize = false
+	info.Dimensions.AdjRestore = false
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"os"
+
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Reservations = &XReservations{Regions: []Reservation{}} // Dynamic reserved regions
+)
+
+type XReservations struct {
+	Display string        // Display fingerprint of loaded regions
+	Regions []Reservation // Reserved regions of current displays
+}
+
+type Reservation struct {
+	Screen uint   // Screen index of reserved region
+	Edge   string // Screen edge of reserved region (top | right | bottom | left)
+	Size   int    // Reserved region size in pixels
+}
+
+func ReservationsLoad() bool {
+	if Workplace == nil || Reservations.Display == Workplace.Displays.Name {
+		return false
+	}
+
+	// Load reserved regions of current display fingerprint
+	Reservations.Display = Workplace.Displays.Name
+	Reservations.Regions = readReservations()
+
+	return true
+}
+
+func ReservationsGet() []Reservation {
+	return Reservations.Regions
+}
+
+func ReserveAdd(screen uint, edge string, size int) bool {
+	if screen >= Workplace.ScreenCount || size < 0 || !common.IsInList(edge, []string{"top", "right", "bottom", "left"}) {
+		log.Warn("Invalid reserved region [", screen, " ", edge, " ", size, "]")
+		return false
+	}
+
+	// Reserved region must leave space on the screen
+	dim := ScreenGeometry(screen)
+	limit := dim.Height
+	if edge == "left" || edge == "right" {
+		limit = dim.Width
+	}
+	if size >= limit {
+		log.Warn("Reserved region exceeds screen [", screen, " ", edge, " ", size, "/", limit, "]")
+		return false
+	}
+
+	// Replace reserved region of same screen edge
+	regions := []Reservation{}
+	for _, r := range ReservationsGet() {
+		if r.Screen != screen || r.Edge != edge {
+			regions = append(regions, r)
+		}
+	}
+	Reservations.Regions = append(regions, Reservation{Screen: screen, Edge: edge, Size: size})
+	writeReservations()
+
+	log.Info("Reserve region ", edge, " ", size, " [", screen, "]")
+
+	return true
+}
+
+func ReserveRemove(screen uint, edge string) bool {
+	regions := []Reservation{}
+
+	// Remove reserved regions of screen (all edges if empty)
+	for _, r := range ReservationsGet() {
+		if r.Screen == screen && (len(edge) == 0 || r.Edge == edge) {
+			continue
+		}
+		regions = append(regions, r)
+	}
+	if len(regions) == len(Reservations.Regions) {
+		return false
+	}
+	Reservations.Regions = regions
+	writeReservations()
+
+	log.Info("Remove reserved region ", edge, " [", screen, "]")
+
+	return true
+}
+
+func ReservationFilePath() string {
+	return filepath.Join(common.CacheWorkplacesPath(), Reservations.Display, "reservations.json")
+}
+
+func readReservations() []Reservation {
+	regions := []Reservation{}
+	if common.CacheDisabled() {
+		return regions
+	}
+
+	// Read reserved regions
+	data, err := os.ReadFile(ReservationFilePath())
+	if err != nil {
+		return regions
+	}
+	if err := json.Unmarshal(data, &regions); err != nil {
+		log.Warn("Error reading reservations file: ", err)
+		return []Reservation{}
+	}
+
+	log.Info("Reserved regions ", regions, " [", Reservations.Display, "]")
+
+	return regions
+}
+
+func writeReservations() {
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Write reserved regions
+	data, err := json.MarshalIndent(Reservations.Regions, "", "  ")
+	if err != nil {
+		return
+	}
+	path := ReservationFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn("Error creating reservations folder: ", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Warn("Error writing reservations file: ", err)
+	}
+}
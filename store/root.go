@@ -2,8 +2,12 @@ package store
 
 import (
 	"fmt"
+	"math"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jezek/xgb/randr"
@@ -12,6 +16,7 @@ import (
 	"github.com/jezek/xgbutil"
 	"github.com/jezek/xgbutil/ewmh"
 	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xinerama"
 	"github.com/jezek/xgbutil/xprop"
 	"github.com/jezek/xgbutil/xrect"
 	"github.com/jezek/xgbutil/xwindow"
@@ -27,32 +32,59 @@ var (
 	Workplace     *XWorkplace     // X workplace
 	Pointer       *XPointer       // X pointer
 	Windows       *XWindows       // X windows
+	Desktops      *History        // X desktop history
+	Idle          *XIdle          // X idle state
+	Events        *XEvents        // X root event queue
+)
+
+var (
+	displaysTimer    *time.Timer // Timer to retry display detection
+	displaysAttempts int         // Number of failed display detections
+)
+
+var (
+	display     string     // X display name of connection
+	pointerLock sync.Mutex // Lock for concurrent pointer access
+)
+
+var (
+	strutTimer *time.Timer                            // Timer to debounce strut changes
+	strutMax   map[xproto.Window]*ewmh.WmStrutPartial // Maximum struts per panel
 )
 
 type XWindowManager struct {
-	Name string // Window manager name
+	Name         string          // Window manager name
+	Supported    []string        // Window manager supported atoms
+	Capabilities map[string]bool // Window manager probed capabilities
+	XWayland     bool            // Window manager runs on XWayland
 }
 
 type XWorkplace struct {
-	DesktopCount   uint      // Number of desktops
-	ScreenCount    uint      // Number of screens
-	CurrentDesktop uint      // Current desktop index
-	CurrentScreen  uint      // Current screen index
-	Displays       XDisplays // Physical connected displays
+	DesktopCount   uint                       // Number of desktops
+	ScreenCount    uint                       // Number of screens
+	CurrentDesktop uint                       // Current desktop index
+	CurrentScreen  uint                       // Current screen index
+	PrimaryOnly    bool                       // Tiling restricted to primary screen
+	Displays       XDisplays                  // Physical connected displays
+	Areas          map[string]common.Geometry // Temporary tiling areas per screen name
 }
 
 type XDisplays struct {
-	Name     string    // Unique heads name (display summary)
-	Screens  []XHead   // Screen dimensions (full display size)
-	Desktops []XHead   // Desktop dimensions (desktop without panels)
-	Corners  []*Corner // Display corners (for pointer events)
+	Name        string    // Unique heads name (display summary or alias)
+	Fingerprint string    // Unique heads name before aliasing
+	Screens     []XHead   // Screen dimensions (full display size)
+	Desktops    []XHead   // Desktop dimensions (desktop without panels)
+	Corners     []*Corner // Display corners (for pointer events)
 }
 
 type XHead struct {
 	Id       uint32          // Head output id (display id)
-	Name     string          // Head output name (display name)
+	Name     string          // Head output name (display name, suffixed for virtual screens)
+	Output   string          // Head output name of the physical output
 	Primary  bool            // Head primary flag (primary display)
+	Scale    float64         // Head scale factor (dpi relative to 96)
 	Geometry common.Geometry // Head dimensions (x/y/width/height)
+	Physical common.Geometry // Head dimensions of the physical output (virtual screens)
 }
 
 type XPointer struct {
@@ -65,6 +97,13 @@ func (p *XPointer) Dragging(dt time.Duration) bool {
 	return p.Drag.Left(dt) || p.Drag.Middle(dt) || p.Drag.Right(dt)
 }
 
+func PointerDragging(dt time.Duration) bool {
+	pointerLock.Lock()
+	defer pointerLock.Unlock()
+
+	return Pointer != nil && Pointer.Dragging(dt)
+}
+
 func (p *XPointer) Pressed() bool {
 	return p.Button.Left || p.Button.Middle || p.Button.Right
 }
@@ -105,6 +144,7 @@ type XWindows struct {
 type XWindow struct {
 	Id       xproto.Window   // Window object id
 	Created  int64           // Internal creation timestamp
+	Frame    xproto.Window   `json:"-"` // Frame window of reparenting window managers
 	Instance *xwindow.Window `json:"-"` // Window object instance
 }
 
@@ -129,6 +169,18 @@ func InitRoot() {
 		log.Fatal("Connection to X server failed: exit")
 	}
 
+	// Init error handler
+	InitErrors()
+
+	// Init instance selection
+	InitSelection()
+
+	// Init window manager capabilities
+	InitCapabilities()
+
+	// Init frame corrections
+	InitCorrections()
+
 	// Init pointer
 	Pointer = PointerGet(X)
 
@@ -145,16 +197,46 @@ func InitRoot() {
 	Workplace.CurrentDesktop = CurrentDesktopGet(X)
 	Workplace.CurrentScreen = ScreenGet(Pointer.Position)
 
+	// Init idle state
+	InitIdle(X)
+
+	// Init desktop history
+	Desktops = CreateHistory(32)
+	Desktops.Push(Workplace.CurrentDesktop)
+
+	// Init event queue
+	InitEvents(stateUpdate)
+
+	// Init rule patterns
+	InitPatterns()
+	common.OnConfigUpdate(InitPatterns)
+
 	// Attach root events
 	root := CreateXWindow(X.RootWin())
 	root.Instance.Listen(xproto.EventMaskSubstructureNotify | xproto.EventMaskPropertyChange)
 	xevent.PropertyNotifyFun(StateUpdate).Connect(X, root.Id)
+
+	// Attach output changes (e.g. laptop lid)
+	InitOutputs()
+
+	// Attach move/resize requests
+	InitMoveResize()
+
+	// Attach strut changes of panels and docks
+	OnPropertyUpdate(func(w xproto.Window, aname string) {
+		if common.IsInList(aname, []string{"_NET_WM_STRUT", "_NET_WM_STRUT_PARTIAL"}) {
+			queueStrutEvent(aname)
+		}
+	})
 }
 
 func Connected() bool {
 	var err error
 	var connected bool
 
+	// Obtain display name
+	display = os.Getenv("DISPLAY")
+
 	// Retry to connect
 	retry := 10
 	for i := 0; i <= retry && !connected; i++ {
@@ -164,7 +246,7 @@ func Connected() bool {
 		}
 
 		// Connect to X server
-		X, err = xgbutil.NewConn()
+		X, err = xgbutil.NewConnDisplay(display)
 		if err != nil {
 			log.Error("Connection to X server failed: ", err)
 			continue
@@ -187,23 +269,40 @@ func Connected() bool {
 
 		// Connection to X established
 		log.Info("Connected to X server on ", common.Process.Host.Hostname, " [", common.Process.Host.Platform, ", ", WindowManager.Name, "]")
-		randr.Init(X.Conn())
+		if err := randr.Init(X.Conn()); err != nil {
+			log.Warn("RandR extension unavailable, fallback to Xinerama or core screen: ", err)
+		}
 		connected = true
 	}
 
 	return connected
 }
 
-func Compatible(feature string) bool {
-	wm := strings.ToLower(WindowManager.Name)
+func InitOutputs() {
+	if !Extension(X, "RANDR") {
+		return
+	}
 
-	// Check feature compatibility
-	switch feature {
-	case "icccm.SizeHintPMinSize":
-		return !strings.Contains(wm, "mutter") && !strings.Contains(wm, "muffin")
+	// Select screen, crtc and output change events
+	mask := uint16(randr.NotifyMaskScreenChange | randr.NotifyMaskCrtcChange | randr.NotifyMaskOutputChange)
+	if err := randr.SelectInput(X.Conn(), X.RootWin(), mask).Check(); err != nil {
+		log.Warn("Error selecting RandR events: ", err)
 	}
+}
 
-	return true
+func Supported(atom string) bool {
+	if WindowManager != nil && len(WindowManager.Supported) > 0 {
+		return common.IsInList(atom, WindowManager.Supported)
+	}
+	supported, err := ewmh.SupportedGet(X)
+
+	// Validate supported atoms
+	if err != nil {
+		log.Warn("Error retrieving supported atoms: ", err)
+		return false
+	}
+
+	return common.IsInList(atom, supported)
 }
 
 func NumberOfDesktopsGet(X *xgbutil.XUtil) uint {
@@ -218,6 +317,21 @@ func NumberOfDesktopsGet(X *xgbutil.XUtil) uint {
 	return deskCount
 }
 
+func NumberOfDesktopsSet(X *xgbutil.XUtil, count uint) bool {
+	if count < 1 || !Capable("desktops") {
+		return false
+	}
+
+	// Request number of desktops
+	err := ewmh.NumberOfDesktopsReq(X, int(count))
+	if err != nil {
+		log.Warn("Error requesting number of desktops: ", err)
+		return false
+	}
+
+	return true
+}
+
 func CurrentDesktopGet(X *xgbutil.XUtil) uint {
 	currentDesk, err := ewmh.CurrentDesktopGet(X)
 
@@ -234,6 +348,38 @@ func CurrentDesktopSet(X *xgbutil.XUtil, desktop uint) {
 	ewmh.CurrentDesktopSet(X, desktop)
 	ewmh.ClientEvent(X, X.RootWin(), "_NET_CURRENT_DESKTOP", int(desktop), int(0))
 	Workplace.CurrentDesktop = desktop
+	Desktops.Push(desktop)
+}
+
+func DesktopNamesGet(X *xgbutil.XUtil) []string {
+	names, err := ewmh.DesktopNamesGet(X)
+
+	// Validate desktop names
+	if err != nil {
+		log.Trace("Error retrieving desktop names: ", err)
+		return []string{}
+	}
+
+	return names
+}
+
+func DesktopNameSet(X *xgbutil.XUtil, desktop uint, name string) {
+	names := DesktopNamesGet(X)
+
+	// Fill up missing desktop names
+	for uint(len(names)) <= desktop {
+		names = append(names, fmt.Sprintf("Workspace %d", len(names)+1))
+	}
+	if len(name) == 0 || names[desktop] == name {
+		return
+	}
+	names[desktop] = name
+
+	// Set desktop names
+	err := ewmh.DesktopNamesSet(X, names)
+	if err != nil {
+		log.Warn("Error setting desktop names: ", err)
+	}
 }
 
 func ActiveWindowGet(X *xgbutil.XUtil) XWindow {
@@ -272,6 +418,40 @@ func ClientListStackingGet(X *xgbutil.XUtil) []XWindow {
 	return windows
 }
 
+func PopupsGet(X *xgbutil.XUtil) []common.Geometry {
+	tree, err := xproto.QueryTree(X.Conn(), X.RootWin()).Reply()
+	if err != nil {
+		log.Warn("Error retrieving window tree: ", err)
+		return nil
+	}
+
+	// Request attributes and geometries in batch
+	attributes := make([]xproto.GetWindowAttributesCookie, len(tree.Children))
+	geometries := make([]xproto.GetGeometryCookie, len(tree.Children))
+	for i, w := range tree.Children {
+		attributes[i] = xproto.GetWindowAttributes(X.Conn(), w)
+		geometries[i] = xproto.GetGeometry(X.Conn(), xproto.Drawable(w))
+	}
+
+	// Obtain mapped override-redirect windows (menus, tooltips)
+	popups := []common.Geometry{}
+	for i := range tree.Children {
+		attr, err := attributes[i].Reply()
+		geom, gerr := geometries[i].Reply()
+		if err != nil || gerr != nil || !attr.OverrideRedirect || attr.MapState != xproto.MapStateViewable {
+			continue
+		}
+		popups = append(popups, common.Geometry{
+			X:      int(geom.X),
+			Y:      int(geom.Y),
+			Width:  int(geom.Width),
+			Height: int(geom.Height),
+		})
+	}
+
+	return popups
+}
+
 func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 	var name string
 
@@ -279,12 +459,17 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 	root := CreateXWindow(X.RootWin())
 	geom, err := root.Instance.Geometry()
 	if err != nil {
-		log.Fatal("Error retrieving root geometry: ", err)
+		return displaysRetry(err)
 	}
 
 	// Get physical heads
-	screens := PhysicalHeadsGet(X)
-	desktops := PhysicalHeadsGet(X)
+	screens, err := PhysicalHeadsGet(X)
+	if err != nil {
+		return displaysRetry(err)
+	}
+	screens = VirtualHeadsGet(screens)
+	desktops := make([]XHead, len(screens))
+	copy(desktops, screens)
 
 	// Get heads name
 	for _, screen := range screens {
@@ -299,12 +484,29 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 		rects = append(rects, desktop.Geometry.Rect())
 	}
 
+	// Forget struts of removed panels
+	stacked := make(map[xproto.Window]bool)
+	for _, w := range Windows.Stacked {
+		stacked[w.Id] = true
+	}
+	for w := range strutMax {
+		if !stacked[w] {
+			delete(strutMax, w)
+		}
+	}
+
 	// Get margins of desktop panels
 	for _, w := range Windows.Stacked {
-		strut, err := ewmh.WmStrutPartialGet(X, w.Id)
+		if !Capable("struts") {
+			break
+		}
+		strut, err := WmStrutGet(X, w.Id)
 		if err != nil {
 			continue
 		}
+		if common.Config.EdgeStrutMax {
+			strut = maxStrut(w.Id, strut)
+		}
 
 		// Apply struts to rectangles in place
 		xrect.ApplyStrut(rects, uint(geom.Width()), uint(geom.Height()),
@@ -319,8 +521,15 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 		desktops[i].Geometry = *common.CreateGeometry(rects[i])
 	}
 
+	// Share cache folder of aliased display setups
+	fingerprint := name
+	if alias := workplaceAlias(fingerprint); alias != fingerprint {
+		log.Info("Alias display setup ", fingerprint, " to ", alias)
+		name = alias
+	}
+
 	// Create display heads
-	heads := XDisplays{Name: name}
+	heads := XDisplays{Name: name, Fingerprint: fingerprint}
 	heads.Screens = screens
 	heads.Desktops = desktops
 	heads.Corners = CreateCorners(screens)
@@ -328,24 +537,264 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 	// Update screen count
 	Workplace.ScreenCount = uint(len(heads.Screens))
 
+	// Reset retry attempts
+	displaysAttempts = 0
+
 	log.Info("Screens ", heads.Screens)
 	log.Info("Desktops ", heads.Desktops)
 
 	return heads
 }
 
-func PhysicalHeadsGet(X *xgbutil.XUtil) []XHead {
+func workplaceAlias(name string) string {
+
+	// Map display setups onto shared cache folders
+	for _, s := range common.Config.CacheAliases {
+		if len(s) < 2 || len(s[1]) == 0 {
+			continue
+		}
+		if matchPattern("^(?:"+s[0]+")$", name) {
+			return s[1]
+		}
+	}
+
+	return name
+}
+
+func displaysRetry(err error) XDisplays {
+	if Workplace == nil || len(Workplace.Displays.Screens) == 0 {
+		log.Fatal("Error retrieving displays: ", err)
+	}
+
+	// Retry with increasing delay (0.5s, 1s, 2s, ..., 30s)
+	delay := time.Duration(500*math.Pow(2, float64(displaysAttempts))) * time.Millisecond
+	if delay > 30*time.Second {
+		delay = 30 * time.Second
+	}
+	displaysAttempts += 1
+
+	log.Warn("Error retrieving displays, retry in ", delay, ": ", err)
+
+	// Schedule retry as root property event
+	if displaysTimer != nil {
+		displaysTimer.Stop()
+	}
+	displaysTimer = time.AfterFunc(delay, func() {
+		QueueEvent("_NET_DESKTOP_GEOMETRY")
+	})
+
+	// Keep last known displays
+	return Workplace.Displays
+}
+
+func WmStrutGet(X *xgbutil.XUtil, w xproto.Window) (*ewmh.WmStrutPartial, error) {
+	strut, err := ewmh.WmStrutPartialGet(X, w)
+	if err == nil {
+		return strut, nil
+	}
+
+	// Fallback to legacy struts spanning the whole screen
+	legacy, err := ewmh.WmStrutGet(X, w)
+	if err != nil {
+		return nil, err
+	}
+	max := uint(math.MaxUint32)
+
+	return &ewmh.WmStrutPartial{
+		Left: legacy.Left, Right: legacy.Right, Top: legacy.Top, Bottom: legacy.Bottom,
+		LeftStartY: 0, LeftEndY: max, RightStartY: 0, RightEndY: max,
+		TopStartX: 0, TopEndX: max, BottomStartX: 0, BottomEndX: max,
+	}, nil
+}
+
+func maxStrut(w xproto.Window, strut *ewmh.WmStrutPartial) *ewmh.WmStrutPartial {
+	if strutMax == nil {
+		strutMax = make(map[xproto.Window]*ewmh.WmStrutPartial)
+	}
+	largest, ok := strutMax[w]
+	if !ok {
+		largest = &ewmh.WmStrutPartial{}
+	}
+
+	// Keep largest strut per edge
+	if strut.Left >= largest.Left {
+		largest.Left, largest.LeftStartY, largest.LeftEndY = strut.Left, strut.LeftStartY, strut.LeftEndY
+	}
+	if strut.Right >= largest.Right {
+		largest.Right, largest.RightStartY, largest.RightEndY = strut.Right, strut.RightStartY, strut.RightEndY
+	}
+	if strut.Top >= largest.Top {
+		largest.Top, largest.TopStartX, largest.TopEndX = strut.Top, strut.TopStartX, strut.TopEndX
+	}
+	if strut.Bottom >= largest.Bottom {
+		largest.Bottom, largest.BottomStartX, largest.BottomEndX = strut.Bottom, strut.BottomStartX, strut.BottomEndX
+	}
+	strutMax[w] = largest
+
+	return largest
+}
+
+func queueStrutEvent(aname string) {
+	delay := common.Config.EdgeStrutDelay
+	if delay <= 0 {
+		QueueEvent(aname)
+		return
+	}
+
+	// Debounce strut changes of auto-hide panels
+	if strutTimer != nil {
+		strutTimer.Stop()
+	}
+	strutTimer = time.AfterFunc(time.Duration(delay)*time.Millisecond, func() {
+		DispatchEvent(func() { QueueEvent(aname) })
+	})
+}
+
+func PhysicalHeadsGet(X *xgbutil.XUtil) ([]XHead, error) {
+	var heads []XHead
+	var err error
+
+	// Get heads from available extensions
+	if Extension(X, "RANDR") {
+		heads, err = randrHeadsGet(X)
+		if err != nil && Workplace != nil && len(Workplace.Displays.Screens) > 0 {
+			return nil, err
+		}
+		if err != nil {
+			log.Warn("Error retrieving RandR heads: ", err)
+		}
+	}
+	if len(heads) == 0 && Extension(X, "XINERAMA") {
+		heads, err = xineramaHeadsGet(X)
+		if err != nil {
+			log.Warn("Error retrieving Xinerama heads: ", err)
+		}
+	}
+	if len(heads) == 0 {
+		log.Info("Fallback to core screen geometry")
+		heads = coreHeadsGet(X)
+	}
+
+	// Collapse mirrored output heads
+	heads = mirroredHeadsCollapse(heads)
+
+	// Sort output heads
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].Geometry.X < heads[j].Geometry.X
+	})
+
+	return heads, nil
+}
+
+func mirroredHeadsCollapse(heads []XHead) []XHead {
+	collapsed := []XHead{}
+
+	for _, head := range heads {
+
+		// Find head with identical geometry
+		mirror := -1
+		for i, c := range collapsed {
+			if c.Geometry == head.Geometry {
+				mirror = i
+				break
+			}
+		}
+		if mirror < 0 {
+			collapsed = append(collapsed, head)
+			continue
+		}
+
+		// Keep primary head of mirrored outputs as one logical screen
+		log.Info("Collapse mirrored outputs ", collapsed[mirror].Name, " and ", head.Name)
+		if head.Primary && !collapsed[mirror].Primary {
+			collapsed[mirror] = head
+		}
+	}
+
+	return collapsed
+}
+
+func VirtualHeadsGet(heads []XHead) []XHead {
+	virtual := []XHead{}
+
+	for _, head := range heads {
+		head.Output = head.Name
+		head.Physical = head.Geometry
+		x, y, w, h := head.Geometry.Pieces()
+
+		// Obtain split boundaries of output (fraction or pixel offset)
+		boundaries := []int{}
+		for _, s := range common.Config.TilingSplit {
+			if len(s) < 2 || (s[0] != head.Name && !(head.Primary && s[0] == "primary")) {
+				continue
+			}
+			for _, value := range s[1:] {
+				b, err := strconv.ParseFloat(value, 64)
+				if err != nil || b <= 0 {
+					log.Warn("Invalid split boundary ", value, " [", head.Name, "]")
+					continue
+				}
+				if b <= 1.0 {
+					b *= float64(w)
+				}
+				if offset := int(math.Round(b)); offset > 0 && offset < w {
+					boundaries = append(boundaries, offset)
+				}
+			}
+		}
+		if len(boundaries) == 0 {
+			virtual = append(virtual, head)
+			continue
+		}
+		sort.Ints(boundaries)
+
+		// Split output into virtual screens side by side
+		boundaries = append(append([]int{0}, boundaries...), w)
+		for i, n := 1, 0; i < len(boundaries); i++ {
+			if boundaries[i] == boundaries[i-1] {
+				continue
+			}
+			part := head
+			part.Name = fmt.Sprintf("%s-%d", head.Name, n)
+			part.Primary = head.Primary && n == 0
+			part.Geometry = common.Geometry{
+				X:      x + boundaries[i-1],
+				Y:      y,
+				Width:  boundaries[i] - boundaries[i-1],
+				Height: h,
+			}
+			virtual = append(virtual, part)
+			n++
+		}
+
+		log.Info("Split output at ", boundaries[1:len(boundaries)-1], " into virtual screens [", head.Name, "]")
+	}
+
+	return virtual
+}
+
+func Extension(X *xgbutil.XUtil, name string) bool {
+	X.Conn().ExtLock.RLock()
+	defer X.Conn().ExtLock.RUnlock()
+
+	// Check if extension is initialized
+	_, ok := X.Conn().Extensions[name]
+
+	return ok
+}
+
+func randrHeadsGet(X *xgbutil.XUtil) ([]XHead, error) {
 
 	// Get screen resources
 	resources, err := randr.GetScreenResources(X.Conn(), X.RootWin()).Reply()
 	if err != nil {
-		log.Fatal("Error retrieving screen resources: ", err)
+		return nil, fmt.Errorf("error retrieving screen resources: %s", err)
 	}
 
 	// Get primary output
 	primary, err := randr.GetOutputPrimary(X.Conn(), X.RootWin()).Reply()
 	if err != nil {
-		log.Fatal("Error retrieving primary screen: ", err)
+		return nil, fmt.Errorf("error retrieving primary screen: %s", err)
 	}
 	hasPrimary := false
 
@@ -355,7 +804,7 @@ func PhysicalHeadsGet(X *xgbutil.XUtil) []XHead {
 	for _, output := range resources.Outputs {
 		oinfo, err := randr.GetOutputInfo(X.Conn(), output, 0).Reply()
 		if err != nil {
-			log.Fatal("Error retrieving screen information: ", err)
+			return nil, fmt.Errorf("error retrieving screen information: %s", err)
 		}
 
 		// Ignored screens (disconnected or off)
@@ -366,19 +815,29 @@ func PhysicalHeadsGet(X *xgbutil.XUtil) []XHead {
 		// Get crtc information (cathode ray tube controller)
 		cinfo, err := randr.GetCrtcInfo(X.Conn(), oinfo.Crtc, 0).Reply()
 		if err != nil {
-			log.Fatal("Error retrieving screen crtc information: ", err)
+			return nil, fmt.Errorf("error retrieving screen crtc information: %s", err)
 		}
 
+		// Physical size of rotated outputs is reported unrotated
+		mm := oinfo.MmWidth
+		if cinfo.Rotation&(randr.RotationRotate90|randr.RotationRotate270) != 0 {
+			mm = oinfo.MmHeight
+		}
+
+		// Crtc size already includes rotation and transformations (e.g. xrandr --scale)
+		width, height := int(cinfo.Width), int(cinfo.Height)
+
 		// Append output heads
 		head := XHead{
 			Id:      uint32(output),
 			Name:    string(oinfo.Name),
 			Primary: primary != nil && output == primary.Output,
+			Scale:   outputScale(string(oinfo.Name), uint32(width), mm),
 			Geometry: common.Geometry{
 				X:      int(cinfo.X),
 				Y:      int(cinfo.Y),
-				Width:  int(cinfo.Width),
-				Height: int(cinfo.Height),
+				Width:  width,
+				Height: height,
 			},
 		}
 		heads = append(heads, head)
@@ -399,12 +858,74 @@ func PhysicalHeadsGet(X *xgbutil.XUtil) []XHead {
 		}
 	}
 
-	// Sort output heads
-	sort.Slice(heads, func(i, j int) bool {
-		return heads[i].Geometry.X < heads[j].Geometry.X
-	})
+	// Outputs may be missing during hotplug
+	if len(heads) == 0 {
+		return nil, fmt.Errorf("no active outputs")
+	}
 
-	return heads
+	return heads, nil
+}
+
+func xineramaHeadsGet(X *xgbutil.XUtil) ([]XHead, error) {
+
+	// Get physical heads
+	rects, err := xinerama.PhysicalHeads(X)
+	if err != nil {
+		return nil, err
+	}
+
+	// Append screen heads, first screen is primary
+	heads := []XHead{}
+	for i, rect := range rects {
+		name := fmt.Sprintf("XINERAMA-%d", i)
+		heads = append(heads, XHead{
+			Id:       uint32(i),
+			Name:     name,
+			Primary:  i == 0,
+			Scale:    outputScale(name, 0, 0),
+			Geometry: *common.CreateGeometry(rect),
+		})
+	}
+
+	return heads, nil
+}
+
+func coreHeadsGet(X *xgbutil.XUtil) []XHead {
+	screen := X.Screen()
+	name := "SCREEN-0"
+
+	// Use core screen as single head
+	return []XHead{{
+		Id:      0,
+		Name:    name,
+		Primary: true,
+		Scale:   outputScale(name, uint32(screen.WidthInPixels), uint32(screen.WidthInMillimeters)),
+		Geometry: common.Geometry{
+			X:      0,
+			Y:      0,
+			Width:  int(screen.WidthInPixels),
+			Height: int(screen.HeightInPixels),
+		},
+	}}
+}
+
+func outputScale(name string, width uint32, mm uint32) float64 {
+
+	// Forced scale from config
+	if scale, ok := common.Config.Scales[name]; ok && scale > 0 {
+		return scale
+	}
+
+	// Ignore unknown physical sizes
+	if !common.Config.WindowScale || width == 0 || mm == 0 {
+		return 1.0
+	}
+
+	// Calculate scale from dpi (rounded to quarter steps)
+	dpi := float64(width) / (float64(mm) / 25.4)
+	scale := math.Round(dpi/96.0*4) / 4
+
+	return math.Min(math.Max(scale, 0.5), 4.0)
 }
 
 func PointerGet(X *xgbutil.XUtil) *XPointer {
@@ -432,14 +953,26 @@ func PointerGet(X *xgbutil.XUtil) *XPointer {
 
 func ScreenGet(p common.Point) uint {
 
-	// Check if point is inside screen rectangle
+	// Check if point is inside screen rectangle (right and bottom edges belong to neighbors)
 	for i, screen := range Workplace.Displays.Screens {
-		if common.IsInsideRect(p, screen.Geometry) {
+		x, y, w, h := screen.Geometry.Pieces()
+		if p.X >= x && p.X < x+w && p.Y >= y && p.Y < y+h {
 			return uint(i)
 		}
 	}
 
-	return 0
+	// Fallback to nearest screen for dead areas between outputs of different size
+	nearest, distance := 0, math.MaxInt
+	for i, screen := range Workplace.Displays.Screens {
+		x, y, w, h := screen.Geometry.Pieces()
+		dx := common.MaxInt(common.MaxInt(x-p.X, 0), p.X-(x+w-1))
+		dy := common.MaxInt(common.MaxInt(y-p.Y, 0), p.Y-(y+h-1))
+		if d := dx*dx + dy*dy; d < distance {
+			nearest, distance = i, d
+		}
+	}
+
+	return uint(nearest)
 }
 
 func ScreenGeometry(i uint) *common.Geometry {
@@ -467,10 +1000,50 @@ func DesktopGeometry(i uint) *common.Geometry {
 		margin = common.Config.EdgeMarginPrimary
 	}
 	if len(margin) == 4 {
-		x += margin[3]
-		y += margin[0]
-		w -= margin[1] + margin[3]
-		h -= margin[2] + margin[0]
+		s := ScreenScale(i)
+		top := int(math.Round(float64(margin[0]) * s))
+		right := int(math.Round(float64(margin[1]) * s))
+		bottom := int(math.Round(float64(margin[2]) * s))
+		left := int(math.Round(float64(margin[3]) * s))
+
+		x += left
+		y += top
+		w -= right + left
+		h -= bottom + top
+	}
+
+	// Add reserved space
+	screen := Workplace.Displays.Screens[i].Geometry
+	for _, r := range common.Config.EdgeReserve {
+		if len(r) != 3 || (r[0] != desktop.Output && !(primaryOutput(desktop.Output) && r[0] == "primary")) {
+			continue
+		}
+		size, err := strconv.Atoi(r[2])
+		if err != nil || size < 0 {
+			log.Warn("Invalid reserved space ", r)
+			continue
+		}
+
+		// Skip inner edges of virtual screens
+		if (r[1] == "left" && screen.X != desktop.Physical.X) || (r[1] == "right" && screen.X+screen.Width != desktop.Physical.X+desktop.Physical.Width) {
+			continue
+		}
+		if !reserveEdge(r[1], size, &x, &y, &w, &h) {
+			log.Warn("Invalid reserved edge ", r)
+		}
+	}
+
+	// Add dynamic reserved regions
+	for _, r := range ReservationsGet() {
+		if r.Screen == i {
+			reserveEdge(r.Edge, r.Size, &x, &y, &w, &h)
+		}
+	}
+
+	// Restrict to temporary tiling area
+	if area, ok := Workplace.Areas[desktop.Name]; ok {
+		x, y = x+area.X, y+area.Y
+		w, h = common.MinInt(area.Width, w-area.X), common.MinInt(area.Height, h-area.Y)
 	}
 
 	return &common.Geometry{
@@ -481,36 +1054,158 @@ func DesktopGeometry(i uint) *common.Geometry {
 	}
 }
 
+func reserveEdge(edge string, size int, x *int, y *int, w *int, h *int) bool {
+	switch edge {
+	case "top":
+		*y += size
+		*h -= size
+	case "right":
+		*w -= size
+	case "bottom":
+		*h -= size
+	case "left":
+		*x += size
+		*w -= size
+	default:
+		return false
+	}
+	return true
+}
+
+func TilingAreaGet(screen uint) (common.Geometry, bool) {
+	if int(screen) >= len(Workplace.Displays.Desktops) {
+		return common.Geometry{}, false
+	}
+	area, ok := Workplace.Areas[Workplace.Displays.Desktops[screen].Name]
+	return area, ok
+}
+
+func TilingAreaSet(screen uint, area *common.Geometry) {
+	if int(screen) >= len(Workplace.Displays.Desktops) {
+		return
+	}
+	name := Workplace.Displays.Desktops[screen].Name
+	if Workplace.Areas == nil {
+		Workplace.Areas = make(map[string]common.Geometry)
+	}
+
+	// Remove temporary tiling area
+	if area == nil {
+		delete(Workplace.Areas, name)
+		log.Info("Reset tiling area [", name, "]")
+		return
+	}
+
+	// Set temporary tiling area relative to desktop geometry
+	Workplace.Areas[name] = *area
+	log.Info("Set tiling area ", *area, " [", name, "]")
+}
+
+func tilingAreaPrune() {
+
+	// Remove temporary tiling areas of disconnected screens
+	for name := range Workplace.Areas {
+		connected := false
+		for _, desktop := range Workplace.Displays.Desktops {
+			if desktop.Name == name {
+				connected = true
+				break
+			}
+		}
+		if !connected {
+			delete(Workplace.Areas, name)
+			log.Info("Reset tiling area [", name, "]")
+		}
+	}
+}
+
+func ScreenTileable(i uint) bool {
+	if int(i) >= len(Workplace.Displays.Screens) {
+		return false
+	}
+	screen := Workplace.Displays.Screens[i]
+
+	// Check runtime restriction
+	if Workplace.PrimaryOnly {
+		return screen.Primary
+	}
+
+	// Check configured outputs
+	outputs := common.Config.TilingOutputs
+	if len(outputs) == 0 {
+		return true
+	}
+
+	return common.IsInList(screen.Output, outputs) || (primaryOutput(screen.Output) && common.IsInList("primary", outputs))
+}
+
+func primaryOutput(output string) bool {
+
+	// Check primary flag of any screen on output
+	for _, screen := range Workplace.Displays.Screens {
+		if screen.Output == output && screen.Primary {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ScreenScale(i uint) float64 {
+	if int(i) >= len(Workplace.Displays.Screens) {
+		return 1.0
+	}
+	screen := Workplace.Displays.Screens[i]
+
+	// Validate screen scale
+	if screen.Scale <= 0 {
+		return 1.0
+	}
+
+	return screen.Scale
+}
+
+func GapSize(i uint) int {
+
+	// Scale gap size by screen dpi
+	return int(math.Round(float64(common.Config.WindowGapSize) * ScreenScale(i)))
+}
+
 func PointerUpdate(X *xgbutil.XUtil) *XPointer {
 	previous := XPointer{XDrag{}, XButton{}, common.Point{}}
 	if Pointer != nil {
 		previous = *Pointer
 	}
 
-	// Update current pointer
-	Pointer = PointerGet(X)
+	// Obtain current pointer
+	current := PointerGet(X)
 
 	// Update current screen
-	Workplace.CurrentScreen = ScreenGet(Pointer.Position)
+	Workplace.CurrentScreen = ScreenGet(current.Position)
 
 	// Update pointer left button drag
-	Pointer.Drag.LeftTime = previous.Drag.LeftTime
-	if Pointer.Button.Left {
-		Pointer.Drag.LeftTime = time.Now().UnixMilli()
+	current.Drag.LeftTime = previous.Drag.LeftTime
+	if current.Button.Left {
+		current.Drag.LeftTime = time.Now().UnixMilli()
 	}
 
 	// Update pointer middle button drag
-	Pointer.Drag.MiddleTime = previous.Drag.MiddleTime
-	if Pointer.Button.Middle {
-		Pointer.Drag.MiddleTime = time.Now().UnixMilli()
+	current.Drag.MiddleTime = previous.Drag.MiddleTime
+	if current.Button.Middle {
+		current.Drag.MiddleTime = time.Now().UnixMilli()
 	}
 
 	// Update pointer right button drag
-	Pointer.Drag.RightTime = previous.Drag.RightTime
-	if Pointer.Button.Right {
-		Pointer.Drag.RightTime = time.Now().UnixMilli()
+	current.Drag.RightTime = previous.Drag.RightTime
+	if current.Button.Right {
+		current.Drag.RightTime = time.Now().UnixMilli()
 	}
 
+	// Update current pointer
+	pointerLock.Lock()
+	Pointer = current
+	pointerLock.Unlock()
+
 	// Pointer callbacks
 	if previous.Button != Pointer.Button {
 		pointerCallbacks(*Pointer, Workplace.CurrentDesktop, Workplace.CurrentScreen)
@@ -528,17 +1223,31 @@ func StateUpdate(X *xgbutil.XUtil, e xevent.PropertyNotifyEvent) {
 		return
 	}
 
+	// Queue root property event
+	if aname == "_NET_WORKAREA" {
+		queueStrutEvent(aname)
+	} else {
+		QueueEvent(aname)
+	}
+}
+
+func stateUpdate(aname string) {
+
 	// Update common state variables
 	if common.IsInList(aname, []string{"_NET_NUMBER_OF_DESKTOPS"}) {
 		Workplace.DesktopCount = NumberOfDesktopsGet(X)
 	} else if common.IsInList(aname, []string{"_NET_CURRENT_DESKTOP"}) {
 		Workplace.CurrentDesktop = CurrentDesktopGet(X)
-	} else if common.IsInList(aname, []string{"_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA"}) {
+		Desktops.Push(Workplace.CurrentDesktop)
+	} else if common.IsInList(aname, []string{"_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA", "_NET_WM_STRUT", "_NET_WM_STRUT_PARTIAL"}) {
 		Workplace.Displays = DisplaysGet(X)
+		tilingAreaPrune()
 	} else if common.IsInList(aname, []string{"_NET_CLIENT_LIST_STACKING"}) {
 		Windows.Stacked = ClientListStackingGet(X)
 	} else if common.IsInList(aname, []string{"_NET_ACTIVE_WINDOW"}) {
 		Windows.Active = ActiveWindowGet(X)
+	} else if common.IsInList(aname, []string{"_NET_SUPPORTED"}) {
+		InitCapabilities()
 	}
 	stateCallbacks(aname, Workplace.CurrentDesktop, Workplace.CurrentScreen)
 }
@@ -0,0 +1,547 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/xprop"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	patterns     map[string]*regexp.Regexp // Compiled regex patterns of config rules
+	patternsLock sync.Mutex                // Lock for concurrent pattern access
+)
+
+type Placement struct {
+	Desktop uint   // Target desktop index
+	Slot    string // Target layout area (master, slave)
+	Follow  bool   // Switch to target desktop
+}
+
+type Title struct {
+	Entry   string // Matching title rule entry
+	Float   bool   // Untrack window while title matches
+	Desktop int    // Target desktop index (-1 = unchanged)
+	Screen  int    // Target screen index (-1 = unchanged)
+}
+
+type Rule struct {
+	Source  string // Rule source (internal, type, state, ignore, window_ignore, window_title, quirk, game_mode, game_classes, window_placement, window_category, window_settle, window_min_size, window_decoration_override)
+	Entry   string // Rule entry that was evaluated
+	Reason  string // Explanation of the rule result
+	Applied bool   // Rule decides the window handling
+}
+
+func InitPatterns() {
+	patternsLock.Lock()
+	patterns = make(map[string]*regexp.Regexp)
+	patternsLock.Unlock()
+
+	// Compile config patterns once per config load
+	errors := PatternErrors()
+	for _, err := range errors {
+		log.Error(err)
+	}
+}
+
+func PatternErrors() []string {
+	exprs := []string{}
+
+	// Collect regex patterns of config rules
+	for _, rules := range [][][]string{
+		common.Config.WindowIgnore,
+		common.Config.WindowPlacement,
+		common.Config.WindowCategory,
+		common.Config.WindowTitle,
+		common.Config.WindowMinSize,
+		common.Config.WindowSettle,
+		common.Config.WindowQuirks,
+		common.Config.WindowDecorationOverride,
+	} {
+		for _, s := range rules {
+			if len(s) > 0 {
+				exprs = append(exprs, s[0])
+			}
+		}
+	}
+	for _, s := range common.Config.WindowIgnore {
+		if len(s) > 1 && len(s[1]) > 0 {
+			exprs = append(exprs, s[1])
+		}
+	}
+	for _, s := range common.Config.WindowTitle {
+		if len(s) > 1 {
+			exprs = append(exprs, s[1])
+		}
+	}
+	exprs = append(exprs, common.Config.GameClasses...)
+	for _, s := range common.Config.CacheAliases {
+		if len(s) > 0 {
+			exprs = append(exprs, "^(?:"+s[0]+")$")
+		}
+	}
+
+	// Validate regex patterns
+	errors := []string{}
+	for _, expr := range exprs {
+		if strings.HasPrefix(expr, "pid:") {
+			continue
+		}
+		expr = strings.TrimPrefix(expr, "exe:")
+		if pattern(expr) == nil {
+			errors = append(errors, "Invalid regex \""+expr+"\" in config")
+		}
+	}
+
+	return errors
+}
+
+func Rules(w xproto.Window, info *Info) []Rule {
+	rules := SpecialRules(info)
+	rules = append(rules, IgnoreRules(info)...)
+	rules = append(rules, GameRules(info)...)
+	rules = append(rules, PlacementRules(w, info)...)
+	return rules
+}
+
+func SpecialRules(info *Info) []Rule {
+	rules := []Rule{}
+
+	// Check internal windows
+	if info.Class == common.Build.Name {
+		rules = append(rules, Rule{Source: "internal", Entry: info.Class, Reason: "Ignore internal window", Applied: true})
+	}
+
+	// Check window types
+	types := []string{
+		"_NET_WM_WINDOW_TYPE_DOCK",
+		"_NET_WM_WINDOW_TYPE_DESKTOP",
+		"_NET_WM_WINDOW_TYPE_TOOLBAR",
+		"_NET_WM_WINDOW_TYPE_UTILITY",
+		"_NET_WM_WINDOW_TYPE_TOOLTIP",
+		"_NET_WM_WINDOW_TYPE_SPLASH",
+		"_NET_WM_WINDOW_TYPE_DIALOG",
+		"_NET_WM_WINDOW_TYPE_COMBO",
+		"_NET_WM_WINDOW_TYPE_NOTIFICATION",
+		"_NET_WM_WINDOW_TYPE_DROPDOWN_MENU",
+		"_NET_WM_WINDOW_TYPE_POPUP_MENU",
+		"_NET_WM_WINDOW_TYPE_MENU",
+		"_NET_WM_WINDOW_TYPE_DND",
+	}
+	for _, typ := range info.Types {
+		if common.IsInList(typ, types) {
+			rules = append(rules, Rule{Source: "type", Entry: typ, Reason: "Ignore window with type " + typ, Applied: true})
+		}
+	}
+
+	// Check window states
+	states := []string{
+		"_NET_WM_STATE_HIDDEN",
+		"_NET_WM_STATE_MODAL",
+		"_NET_WM_STATE_ABOVE",
+		"_NET_WM_STATE_BELOW",
+		"_NET_WM_STATE_SKIP_PAGER",
+		"_NET_WM_STATE_SKIP_TASKBAR",
+	}
+	for _, state := range info.States {
+		if common.IsInList(state, states) {
+			rules = append(rules, Rule{Source: "state", Entry: state, Reason: "Ignore window with state " + state, Applied: true})
+		}
+	}
+
+	return rules
+}
+
+func IgnoreRules(info *Info) []Rule {
+	rules := []Rule{}
+
+	// Check invalid windows
+	if len(info.Class) == 0 {
+		return append(rules, Rule{Source: "internal", Reason: "Ignore invalid window", Applied: true})
+	}
+
+	// Check runtime ignored windows
+	if common.IsIgnoredClass(info.Class) {
+		rules = append(rules, Rule{Source: "ignore", Entry: strings.ToLower(info.Class), Reason: "Ignore window with class from " + common.IgnoreFilePath(), Applied: true})
+	}
+
+	// Check ignored windows
+	for _, s := range common.Config.WindowIgnore {
+		conf_class := s[0]
+		conf_name := s[1]
+		entry := strings.TrimSpace(strings.Join(s, " "))
+
+		// Ignore all windows with this class
+		class_match := matchClass(conf_class, info)
+		if !class_match {
+			continue
+		}
+
+		// But allow the window with a special name
+		name_match := conf_name != "" && matchPattern(conf_name, info.Name)
+		if name_match {
+			rules = append(rules, Rule{Source: "window_ignore", Entry: entry, Reason: "Allow window with class and name exception " + entry + " from config"})
+		} else {
+			rules = append(rules, Rule{Source: "window_ignore", Entry: entry, Reason: "Ignore window with " + entry + " from config", Applied: true})
+		}
+	}
+
+	// Check floating title rules
+	if title := TitleGet(info); title != nil && title.Float {
+		rules = append(rules, Rule{Source: "window_title", Entry: title.Entry, Reason: "Float window with title " + title.Entry + " from config", Applied: true})
+	}
+
+	// Check floating quirks
+	if quirk := QuirkGet(info); quirk.Float {
+		rules = append(rules, Rule{Source: "quirk", Entry: quirk.Class, Reason: "Float window with quirk " + quirk.Class, Applied: true})
+	}
+
+	return rules
+}
+
+func GameRules(info *Info) []Rule {
+	rules := []Rule{}
+	if !common.Config.GameMode || len(info.Class) == 0 {
+		return rules
+	}
+
+	// Check fullscreen windows
+	if IsFullscreen(info) {
+		rules = append(rules, Rule{Source: "game_mode", Entry: "_NET_WM_STATE_FULLSCREEN", Reason: "Suspend tiling for fullscreen window", Applied: true})
+	}
+
+	// Check game windows
+	for _, s := range common.Config.GameClasses {
+		if matchClass(s, info) {
+			rules = append(rules, Rule{Source: "game_classes", Entry: s, Reason: "Suspend tiling for game window with class " + s, Applied: true})
+		}
+	}
+
+	return rules
+}
+
+func PlacementRules(w xproto.Window, info *Info) []Rule {
+	rules := []Rule{}
+	if len(info.Class) == 0 {
+		return rules
+	}
+
+	// Check startup and category placement
+	if placement := PlacementGet(info); placement != nil {
+		rules = append(rules, Rule{Source: "window_placement", Entry: fmt.Sprint(placement.Desktop, " ", placement.Slot), Reason: fmt.Sprint("Place window on desktop ", placement.Desktop, " from config")})
+	}
+	if category := CategoryGet(w, info); category != nil {
+		rules = append(rules, Rule{Source: "window_category", Entry: fmt.Sprint(category.Desktop, " ", category.Follow), Reason: fmt.Sprint("Send new window to desktop ", category.Desktop, " from config")})
+	}
+
+	// Check title rules
+	if title := TitleGet(info); title != nil && !title.Float {
+		rules = append(rules, Rule{Source: "window_title", Entry: title.Entry, Reason: "Move window with title " + title.Entry + " from config"})
+	}
+
+	// Check settle, size and decoration rules
+	if delay := SettleGet(info); delay > 0 {
+		rules = append(rules, Rule{Source: "window_settle", Entry: delay.String(), Reason: "Delay tracking of window by " + delay.String()})
+	}
+	if width, height := MinSizeGet(info); width > 0 || height > 0 {
+		rules = append(rules, Rule{Source: "window_min_size", Entry: fmt.Sprint(width, " ", height), Reason: fmt.Sprint("Limit minimum tile size to ", width, "x", height, " from config")})
+	}
+	if decoration := DecorationGet(info); len(decoration) > 0 {
+		rules = append(rules, Rule{Source: "window_decoration_override", Entry: decoration, Reason: "Override decoration toggle with " + decoration + " from config"})
+	}
+
+	// Check quirks
+	if quirk := QuirkGet(info); len(quirk.Class) > 0 && !quirk.Float {
+		rules = append(rules, Rule{Source: "quirk", Entry: quirk.Class, Reason: "Apply quirk " + quirk.Class})
+	}
+
+	return rules
+}
+
+func PlacementGet(info *Info) *Placement {
+	if common.Config.WindowPlacementTime <= 0 || len(info.Class) == 0 {
+		return nil
+	}
+
+	// Check startup time period
+	started := time.UnixMilli(common.Process.Started)
+	if time.Since(started) > time.Duration(common.Config.WindowPlacementTime)*time.Second {
+		return nil
+	}
+
+	// Check placed windows
+	for _, s := range common.Config.WindowPlacement {
+		if len(s) < 2 {
+			continue
+		}
+		conf_class := s[0]
+		conf_desktop := s[1]
+		conf_slot := ""
+		if len(s) > 2 {
+			conf_slot = strings.ToLower(s[2])
+		}
+
+		if !matchClass(conf_class, info) {
+			continue
+		}
+
+		// Validate desktop index
+		desktop, err := strconv.Atoi(conf_desktop)
+		if err != nil || desktop < 0 || uint(desktop) >= Workplace.DesktopCount {
+			log.Warn("Invalid placement desktop ", conf_desktop, " [", info.Class, "]")
+			return nil
+		}
+
+		log.Debug("Place window on desktop ", desktop, " ", conf_slot, " [", info.Class, "]")
+
+		return &Placement{Desktop: uint(desktop), Slot: conf_slot}
+	}
+
+	return nil
+}
+
+func CategoryGet(w xproto.Window, info *Info) *Placement {
+	if len(info.Class) == 0 {
+		return nil
+	}
+
+	// Obtain application ids
+	ids := []string{}
+	for _, atom := range []string{"_GTK_APPLICATION_ID", "_KDE_NET_WM_DESKTOP_FILE"} {
+		id, err := xprop.PropValStr(PropertyGet(w, atom))
+		if err == nil && len(id) > 0 {
+			ids = append(ids, strings.ToLower(id))
+		}
+	}
+
+	// Check categorized windows
+	for _, s := range common.Config.WindowCategory {
+		if len(s) < 4 {
+			continue
+		}
+		conf_match := s[0]
+		conf_desktop := s[1]
+		conf_follow := s[2]
+		conf_enabled := s[3]
+
+		if enabled, err := strconv.ParseBool(conf_enabled); err != nil || !enabled {
+			continue
+		}
+
+		matched := matchClass(conf_match, info)
+		for _, id := range ids {
+			matched = matched || matchPattern(conf_match, id)
+		}
+		if !matched {
+			continue
+		}
+
+		// Validate desktop index
+		desktop, err := strconv.Atoi(conf_desktop)
+		if err != nil || desktop < 0 || uint(desktop) >= Workplace.DesktopCount {
+			log.Warn("Invalid category desktop ", conf_desktop, " [", info.Class, "]")
+			return nil
+		}
+		follow, _ := strconv.ParseBool(conf_follow)
+
+		log.Debug("Match category desktop ", desktop, " [", info.Class, "]")
+
+		return &Placement{Desktop: uint(desktop), Follow: follow}
+	}
+
+	return nil
+}
+
+func TitleGet(info *Info) *Title {
+	if len(info.Class) == 0 {
+		return nil
+	}
+
+	// Check title rules
+	for _, s := range common.Config.WindowTitle {
+		if len(s) < 3 {
+			continue
+		}
+		conf_class := s[0]
+		conf_title := s[1]
+		conf_actions := strings.ToLower(s[2])
+		entry := strings.TrimSpace(strings.Join(s, " "))
+
+		if !matchClass(conf_class, info) || !matchPattern(conf_title, info.Name) {
+			continue
+		}
+
+		// Parse comma separated actions
+		title := &Title{Entry: entry, Desktop: -1, Screen: -1}
+		for _, action := range strings.Split(conf_actions, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(action), "=")
+			index, err := strconv.Atoi(value)
+			switch {
+			case key == "float":
+				title.Float = true
+			case key == "desktop" && err == nil && index >= 0:
+				title.Desktop = index
+			case key == "screen" && err == nil && index >= 0:
+				title.Screen = index
+			default:
+				log.Warn("Invalid title action ", action, " [", info.Class, "]")
+			}
+		}
+
+		return title
+	}
+
+	return nil
+}
+
+func MinSizeGet(info *Info) (int, int) {
+	if len(info.Class) == 0 {
+		return 0, 0
+	}
+
+	// Check minimum tile sizes
+	for _, s := range common.Config.WindowMinSize {
+		if len(s) < 3 {
+			continue
+		}
+		conf_class := s[0]
+		conf_width := s[1]
+		conf_height := s[2]
+
+		if !matchClass(conf_class, info) {
+			continue
+		}
+
+		// Validate minimum dimensions
+		width, errw := strconv.Atoi(conf_width)
+		height, errh := strconv.Atoi(conf_height)
+		if errw != nil || errh != nil || width < 0 || height < 0 {
+			log.Warn("Invalid minimum size ", s, " [", info.Class, "]")
+			return 0, 0
+		}
+
+		return width, height
+	}
+
+	return 0, 0
+}
+
+func DecorationGet(info *Info) string {
+	if len(info.Class) == 0 {
+		return ""
+	}
+
+	// Check decoration overrides
+	for _, s := range common.Config.WindowDecorationOverride {
+		if len(s) < 2 {
+			continue
+		}
+		conf_class := s[0]
+		conf_decoration := strings.ToLower(s[1])
+
+		if !matchClass(conf_class, info) {
+			continue
+		}
+
+		// Validate decoration value
+		if !common.IsInList(conf_decoration, []string{"keep", "strip"}) {
+			log.Warn("Invalid decoration override ", conf_decoration, " [", info.Class, "]")
+			return ""
+		}
+
+		return conf_decoration
+	}
+
+	return ""
+}
+
+func SettleGet(info *Info) time.Duration {
+	if len(info.Class) == 0 {
+		return 0
+	}
+
+	// Check delayed windows
+	for _, s := range common.Config.WindowSettle {
+		if len(s) < 2 {
+			continue
+		}
+		conf_class := s[0]
+		conf_delay := s[1]
+
+		if !matchClass(conf_class, info) {
+			continue
+		}
+
+		// Validate delay value
+		delay, err := strconv.Atoi(conf_delay)
+		if err != nil || delay < 0 {
+			log.Warn("Invalid settle delay ", conf_delay, " [", info.Class, "]")
+			return 0
+		}
+
+		return time.Duration(delay) * time.Millisecond
+	}
+
+	return QuirkGet(info).Settle
+}
+
+func matchClass(conf string, info *Info) bool {
+
+	// Match process id
+	if pid, ok := strings.CutPrefix(conf, "pid:"); ok {
+		return info.Process.Id > 0 && pid == strconv.FormatUint(uint64(info.Process.Id), 10)
+	}
+
+	// Match process executable name
+	if exe, ok := strings.CutPrefix(conf, "exe:"); ok {
+		return len(info.Process.Name) > 0 && matchPattern(exe, info.Process.Name)
+	}
+
+	// Match window class
+	return matchPattern(conf, info.Class)
+}
+
+func matchPattern(expr string, value string) bool {
+	reg := pattern(expr)
+	return reg != nil && reg.MatchString(strings.ToLower(value))
+}
+
+func pattern(expr string) *regexp.Regexp {
+	patternsLock.Lock()
+	defer patternsLock.Unlock()
+
+	// Obtain compiled pattern (nil if invalid)
+	if patterns == nil {
+		patterns = make(map[string]*regexp.Regexp)
+	}
+	if reg, ok := patterns[expr]; ok {
+		return reg
+	}
+	reg, err := regexp.Compile(strings.ToLower(expr))
+	if err != nil {
+		reg = nil
+	}
+	patterns[expr] = reg
+
+	return reg
+}
+
+func applied(rules []Rule, info *Info) bool {
+	for _, rule := range rules {
+		if rule.Applied {
+			log.Info(rule.Reason, " [", info.Class, "]")
+			return true
+		}
+	}
+	return false
+}
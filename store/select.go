@@ -0,0 +1,72 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/xcursor"
+)
+
+func SelectWindow() (xproto.Window, error) {
+
+	// Use temporary connection to grab the pointer
+	xu, err := xgbutil.NewConn()
+	if err != nil {
+		return 0, err
+	}
+	defer xu.Conn().Close()
+
+	// Grab pointer with crosshair cursor
+	cursor, err := xcursor.CreateCursor(xu, xcursor.Crosshair)
+	if err != nil {
+		return 0, err
+	}
+	grab, err := xproto.GrabPointer(xu.Conn(), false, xu.RootWin(), xproto.EventMaskButtonPress,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, xproto.WindowNone, cursor, xproto.TimeCurrentTime).Reply()
+	if err != nil {
+		return 0, err
+	}
+	if grab.Status != xproto.GrabStatusSuccess {
+		return 0, errors.New("pointer is grabbed by another client")
+	}
+	defer xproto.UngrabPointer(xu.Conn(), xproto.TimeCurrentTime)
+
+	// Wait for pointer click
+	for {
+		ev, xerr := xu.Conn().WaitForEvent()
+		if ev == nil && xerr == nil {
+			return 0, errors.New("connection closed")
+		}
+		if press, ok := ev.(xproto.ButtonPressEvent); ok {
+			if press.Child == xproto.WindowNone {
+				return xu.RootWin(), nil
+			}
+			w, _ := clientWindow(xu, press.Child)
+			return w, nil
+		}
+	}
+}
+
+func clientWindow(xu *xgbutil.XUtil, w xproto.Window) (xproto.Window, bool) {
+
+	// Check for managed client window
+	if _, err := icccm.WmStateGet(xu, w); err == nil {
+		return w, true
+	}
+
+	// Search client window within frame window
+	tree, err := xproto.QueryTree(xu.Conn(), w).Reply()
+	if err != nil {
+		return w, false
+	}
+	for _, child := range tree.Children {
+		if c, ok := clientWindow(xu, child); ok {
+			return c, true
+		}
+	}
+
+	return w, false
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	selectionCallbacksFun []func(xproto.Window) // Selection events callback functions
+)
+
+func InitSelection() {
+	owner, err := selectionOwner(X)
+	if err != nil {
+		log.Fatal("Error retrieving selection owner: ", err)
+	}
+
+	// Check for running instance
+	if owner != 0 && !common.Args.Replace {
+		log.Fatal("Selection ", SelectionName(X), " owned by running instance [", owner, "]: exit")
+	}
+
+	// Acquire selection ownership
+	win, err := acquireSelection(X)
+	if err != nil {
+		log.Fatal("Error acquiring selection ", SelectionName(X), ": ", err)
+	}
+
+	// Attach selection events
+	xevent.SelectionClearFun(func(X *xgbutil.XUtil, ev xevent.SelectionClearEvent) {
+		if owner, err := selectionOwner(X); err == nil && owner != win.Id {
+			selectionCallbacks(owner)
+		}
+	}).Connect(X, win.Id)
+}
+
+func ReplaceInstance() bool {
+	xu, err := xgbutil.NewConn()
+	if err != nil {
+		log.Warn("Connection to X server failed: ", err)
+		return false
+	}
+	defer xu.Conn().Close()
+
+	// Take over selection of running instance
+	_, err = acquireSelection(xu)
+	if err != nil {
+		log.Warn("Error acquiring selection ", SelectionName(xu), ": ", err)
+		return false
+	}
+
+	return true
+}
+
+func SelectionName(xu *xgbutil.XUtil) string {
+	return fmt.Sprintf("_%s_S%d", strings.ToUpper(common.Build.Name), xu.Conn().DefaultScreen)
+}
+
+func OnSelectionUpdate(fun func(xproto.Window)) {
+	selectionCallbacksFun = append(selectionCallbacksFun, fun)
+}
+
+func acquireSelection(xu *xgbutil.XUtil) (*xwindow.Window, error) {
+	atom, err := xprop.Atm(xu, SelectionName(xu))
+	if err != nil {
+		return nil, err
+	}
+	owner, err := selectionOwner(xu)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create selection window
+	win, err := xwindow.Generate(xu)
+	if err != nil {
+		return nil, err
+	}
+	err = win.CreateChecked(xu.RootWin(), -1, -1, 1, 1, xproto.CwOverrideRedirect, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request selection ownership
+	err = xproto.SetSelectionOwnerChecked(xu.Conn(), win.Id, atom, xproto.TimeCurrentTime).Check()
+	if err != nil {
+		return nil, err
+	}
+	if current, err := selectionOwner(xu); err != nil || current != win.Id {
+		return nil, fmt.Errorf("selection ownership denied")
+	}
+
+	// Wait for previous owner to exit
+	if owner != 0 {
+		log.Info("Replace running instance [", owner, "]")
+		for i := 0; i < 100; i++ {
+			if _, err := xproto.GetGeometry(xu.Conn(), xproto.Drawable(owner)).Reply(); err != nil {
+				return win, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return nil, fmt.Errorf("running instance did not exit")
+	}
+
+	return win, nil
+}
+
+func selectionOwner(xu *xgbutil.XUtil) (xproto.Window, error) {
+	atom, err := xprop.Atm(xu, SelectionName(xu))
+	if err != nil {
+		return 0, err
+	}
+	reply, err := xproto.GetSelectionOwner(xu.Conn(), atom).Reply()
+	if err != nil {
+		return 0, err
+	}
+
+	return reply.Owner, nil
+}
+
+func selectionCallbacks(owner xproto.Window) {
+	log.Info("Selection event [", owner, "]")
+
+	for _, fun := range selectionCallbacksFun {
+		fun(owner)
+	}
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"encoding/binary"
+	"path/filepath"
+)
+
+type WlConn struct {
+	Socket   *net.UnixConn               // Wayland display socket
+	Next     uint32                      // Next free client object id
+	Handlers map[uint32]func(*WlMessage) // Event handlers by object id
+	Globals  map[string]WlGlobal         // Announced registry globals by interface
+	Registry uint32                      // Registry object id
+	Error    error                       // Fatal protocol error reported by compositor
+}
+
+type WlGlobal struct {
+	Name    uint32 // Global name used for binding
+	Version uint32 // Global interface version
+}
+
+type WlMessage struct {
+	Object uint32 // Object id the event belongs to
+	Opcode uint16 // Event opcode within object interface
+	Data   []byte // Remaining event arguments
+}
+
+func WlSocketPath() string {
+	display := os.Getenv("WAYLAND_DISPLAY")
+	if len(display) == 0 {
+		return ""
+	}
+	if filepath.IsAbs(display) {
+		return display
+	}
+
+	return filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), display)
+}
+
+func WlConnect() (*WlConn, error) {
+	path := WlSocketPath()
+	if len(path) == 0 {
+		return nil, errors.New("WAYLAND_DISPLAY not set")
+	}
+
+	// Connect to compositor socket
+	socket, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	conn := &WlConn{
+		Socket:   socket,
+		Next:     2,
+		Handlers: make(map[uint32]func(*WlMessage)),
+		Globals:  make(map[string]WlGlobal),
+	}
+
+	// Handle display errors
+	conn.Handlers[1] = func(m *WlMessage) {
+		if m.Opcode == 0 {
+			object, code := m.Uint(), m.Uint()
+			conn.Error = fmt.Errorf("wayland error on object %d (%d): %s", object, code, m.String())
+		}
+	}
+
+	// Collect registry globals
+	conn.Registry = conn.NewId(func(m *WlMessage) {
+		if m.Opcode == 0 {
+			name, iface, version := m.Uint(), m.String(), m.Uint()
+			conn.Globals[iface] = WlGlobal{Name: name, Version: version}
+		}
+	})
+	if err := conn.Request(1, 1, conn.Registry); err != nil {
+		socket.Close()
+		return nil, err
+	}
+	if err := conn.Roundtrip(); err != nil {
+		socket.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (conn *WlConn) NewId(handler func(*WlMessage)) uint32 {
+	id := conn.Next
+	conn.Next += 1
+	conn.Handlers[id] = handler
+	return id
+}
+
+func (conn *WlConn) Bind(iface string, version uint32, handler func(*WlMessage)) (uint32, bool) {
+	global, ok := conn.Globals[iface]
+	if !ok {
+		return 0, false
+	}
+	if global.Version < version {
+		version = global.Version
+	}
+
+	// Bind global to new object id
+	id := conn.NewId(handler)
+	err := conn.Request(conn.Registry, 0, global.Name, iface, version, id)
+
+	return id, err == nil
+}
+
+func (conn *WlConn) Request(object uint32, opcode uint16, args ...interface{}) error {
+	data := []byte{}
+
+	// Encode request arguments
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case uint32:
+			data = binary.LittleEndian.AppendUint32(data, v)
+		case string:
+			size := len(v) + 1
+			data = binary.LittleEndian.AppendUint32(data, uint32(size))
+			data = append(data, v...)
+			data = append(data, make([]byte, wlPadding(size)-len(v))...)
+		}
+	}
+
+	// Encode request header
+	header := binary.LittleEndian.AppendUint32([]byte{}, object)
+	header = binary.LittleEndian.AppendUint32(header, uint32(8+len(data))<<16|uint32(opcode))
+
+	_, err := conn.Socket.Write(append(header, data...))
+	return err
+}
+
+func (conn *WlConn) Roundtrip() error {
+	done := false
+
+	// Request sync callback
+	callback := conn.NewId(func(m *WlMessage) {
+		done = true
+	})
+	if err := conn.Request(1, 0, callback); err != nil {
+		return err
+	}
+
+	// Dispatch events until callback is done
+	for !done {
+		if err := conn.Dispatch(); err != nil {
+			return err
+		}
+	}
+	delete(conn.Handlers, callback)
+
+	return nil
+}
+
+func (conn *WlConn) Dispatch() error {
+	header := make([]byte, 8)
+
+	// Read event header
+	if _, err := wlRead(conn.Socket, header); err != nil {
+		return err
+	}
+	object := binary.LittleEndian.Uint32(header[0:4])
+	word := binary.LittleEndian.Uint32(header[4:8])
+
+	// Validate event size
+	size := int(word >> 16)
+	if size < 8 || size%4 != 0 {
+		return fmt.Errorf("invalid wayland event size %d on object %d", size, object)
+	}
+
+	// Read event arguments
+	data := make([]byte, size-8)
+	if _, err := wlRead(conn.Socket, data); err != nil {
+		return err
+	}
+
+	// Call object handler
+	if handler, ok := conn.Handlers[object]; ok {
+		handler(&WlMessage{Object: object, Opcode: uint16(word), Data: data})
+	}
+
+	return conn.Error
+}
+
+func (conn *WlConn) Close() error {
+	return conn.Socket.Close()
+}
+
+func (m *WlMessage) Uint() uint32 {
+	if len(m.Data) < 4 {
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(m.Data)
+	m.Data = m.Data[4:]
+	return v
+}
+
+func (m *WlMessage) String() string {
+	b := m.Array()
+	if len(b) == 0 {
+		return ""
+	}
+	return string(b[:len(b)-1])
+}
+
+func (m *WlMessage) Array() []byte {
+	size := int(m.Uint())
+	padded := wlPadding(size)
+	if size > len(m.Data) || padded > len(m.Data) {
+		return []byte{}
+	}
+	v := m.Data[:size]
+	m.Data = m.Data[padded:]
+	return v
+}
+
+func wlPadding(size int) int {
+	return (size + 3) &^ 3
+}
+
+func wlRead(socket *net.UnixConn, data []byte) (int, error) {
+	read := 0
+	for read < len(data) {
+		n, err := socket.Read(data[read:])
+		if err != nil {
+			return read, err
+		}
+		read += n
+	}
+	return read, nil
+}
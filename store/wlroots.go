@@ -0,0 +1,201 @@
+package store
+
+import (
+	"encoding/binary"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	WlToplevelStateMaximized  = 0 // Toplevel state maximized
+	WlToplevelStateFullscreen = 3 // Toplevel state fullscreen
+)
+
+type WlrootsBackend struct {
+	Conn       *WlConn                 // Wayland compositor connection
+	Toplevels  map[uint32]*WlToplevel  // Foreign toplevel handles
+	Workspaces map[uint32]*WlWorkspace // Workspace handles
+}
+
+type WlToplevel struct {
+	Id      uint32   // Toplevel object id
+	Title   string   // Toplevel title
+	AppId   string   // Toplevel application id
+	States  []uint32 // Toplevel states
+	Managed bool     // Toplevel layout was applied
+}
+
+type WlWorkspace struct {
+	Id     uint32 // Workspace object id
+	Name   string // Workspace name
+	Active bool   // Workspace is active
+}
+
+func InitWlroots() *WlrootsBackend {
+	b := &WlrootsBackend{}
+
+	// Require explicit opt-in
+	if !common.HasFlag("enable-experimental-backend") {
+		log.Fatal("Backend \"wlroots\" is experimental and does not tile, run with \"enable-experimental-backend\" to use it anyway")
+	}
+	if !b.Available() {
+		log.Warn("Backend \"wlroots\" is not available")
+	}
+	b.Init()
+
+	return b
+}
+
+func (b *WlrootsBackend) Available() bool {
+	conn, err := WlConnect()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	// Check for foreign toplevel management
+	_, ok := conn.Globals["zwlr_foreign_toplevel_manager_v1"]
+
+	return ok
+}
+
+func (b *WlrootsBackend) Init() {
+	conn, err := WlConnect()
+	if err != nil {
+		log.Fatal("Error connecting to wayland compositor: ", err)
+	}
+	b.Conn = conn
+	b.Toplevels = make(map[uint32]*WlToplevel)
+	b.Workspaces = make(map[uint32]*WlWorkspace)
+
+	// Bind foreign toplevel manager
+	if _, ok := conn.Bind("zwlr_foreign_toplevel_manager_v1", 3, b.onManager); !ok {
+		log.Fatal("Wayland compositor does not support zwlr_foreign_toplevel_manager_v1")
+	}
+
+	// Bind workspace manager
+	if _, ok := conn.Bind("ext_workspace_manager_v1", 1, b.onWorkspaceManager); !ok {
+		log.Warn("Wayland compositor does not support ext_workspace_manager_v1")
+	}
+
+	// Geometry based layouts can't be applied
+	layout := common.Config.TilingLayout
+	if !common.IsInList(layout, []string{"maximized", "fullscreen"}) {
+		log.Warn("Experimental wlroots backend supports maximized and fullscreen layouts only, ignore \"", layout, "\"")
+	}
+
+	log.Info("Connected to wayland compositor [", WlSocketPath(), "]")
+}
+
+func (b *WlrootsBackend) Loop() bool {
+	defer b.Conn.Close()
+
+	// Dispatch compositor events
+	for {
+		if err := b.Conn.Dispatch(); err != nil {
+			log.Warn("Wayland connection closed: ", err)
+			return true
+		}
+	}
+}
+
+func (b *WlrootsBackend) Apply(t *WlToplevel) {
+	if t.Managed || !common.Config.TilingEnabled {
+		return
+	}
+	t.Managed = true
+
+	// Ignore maximized and fullscreen windows
+	for _, state := range t.States {
+		if state == WlToplevelStateMaximized || state == WlToplevelStateFullscreen {
+			return
+		}
+	}
+
+	// Ignore windows from config
+	if IsIgnored(&Info{Class: t.AppId, Name: t.Title}) {
+		return
+	}
+
+	// Apply single window layouts
+	switch common.Config.TilingLayout {
+	case "maximized":
+		log.Info("Maximize toplevel [", t.AppId, "]")
+		if !common.Args.DryRun {
+			b.Conn.Request(t.Id, 0)
+		}
+	case "fullscreen":
+		log.Info("Fullscreen toplevel [", t.AppId, "]")
+		if !common.Args.DryRun {
+			b.Conn.Request(t.Id, 8, uint32(0))
+		}
+	}
+}
+
+func (b *WlrootsBackend) onManager(m *WlMessage) {
+	if m.Opcode != 0 {
+		return
+	}
+
+	// Track new toplevel handle
+	t := &WlToplevel{}
+	t.Id = m.Uint()
+	b.Toplevels[t.Id] = t
+	b.Conn.Handlers[t.Id] = func(m *WlMessage) {
+		b.onToplevel(t, m)
+	}
+}
+
+func (b *WlrootsBackend) onToplevel(t *WlToplevel, m *WlMessage) {
+	switch m.Opcode {
+	case 0:
+		t.Title = m.String()
+	case 1:
+		t.AppId = m.String()
+	case 4:
+		t.States = []uint32{}
+		states := m.Array()
+		for i := 0; i+4 <= len(states); i += 4 {
+			t.States = append(t.States, binary.LittleEndian.Uint32(states[i:]))
+		}
+	case 5:
+		log.Debug("Toplevel updated ", t.States, " [", t.AppId, "]")
+		b.Apply(t)
+	case 6:
+		log.Debug("Toplevel closed [", t.AppId, "]")
+		b.Conn.Request(t.Id, 7)
+		delete(b.Toplevels, t.Id)
+		delete(b.Conn.Handlers, t.Id)
+	}
+}
+
+func (b *WlrootsBackend) onWorkspaceManager(m *WlMessage) {
+	if m.Opcode != 1 {
+		return
+	}
+
+	// Track new workspace handle
+	w := &WlWorkspace{}
+	w.Id = m.Uint()
+	b.Workspaces[w.Id] = w
+	b.Conn.Handlers[w.Id] = func(m *WlMessage) {
+		b.onWorkspace(w, m)
+	}
+}
+
+func (b *WlrootsBackend) onWorkspace(w *WlWorkspace, m *WlMessage) {
+	switch m.Opcode {
+	case 1:
+		w.Name = m.String()
+	case 3:
+		w.Active = m.Uint()&1 != 0
+		if w.Active {
+			log.Info("Active workspace updated [", w.Name, "]")
+		}
+	case 5:
+		delete(b.Workspaces, w.Id)
+		delete(b.Conn.Handlers, w.Id)
+	}
+}
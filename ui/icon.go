@@ -2,7 +2,9 @@ package ui
 
 import (
 	"bytes"
+	"fmt"
 	"image"
+	"time"
 
 	"image/color"
 	"image/draw"
@@ -37,42 +39,9 @@ func UpdateIcon(ws *desktop.Workspace) {
 		name = "disabled"
 	}
 
-	// Initialize image
-	col := image.Uniform{rgba("icon_foreground")}
-	icon := image.NewRGBA(image.Rect(0, 0, iconSize, iconSize))
-
-	// Draw background rectangle
-	x0, y0, x1, y1 := iconMargin, iconMargin, iconSize-iconMargin, iconSize-iconMargin
-	draw.Draw(icon, icon.Bounds(), &image.Uniform{rgba("icon_background")}, image.Point{}, draw.Src)
-
-	// Draw layout rectangles
-	switch name {
-	case "vertical-left":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "vertical-right":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y1), &col, image.Point{}, draw.Src)
-	case "horizontal-top":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "horizontal-bottom":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "maximized":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/5-layoutMargin/2), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/5+layoutMargin/2, x1, y1), &col, image.Point{}, draw.Src)
-	case "fullscreen":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y1), &col, image.Point{}, draw.Src)
-	case "disabled":
-		draw.Draw(icon, image.Rect(x0, y0, x0+2*layoutMargin, y1-2*layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0, x1-2*layoutMargin, y0+2*layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+2*layoutMargin+20, y0+2*layoutMargin+20, x1, y1), &col, image.Point{}, draw.Src)
-	}
+	// Draw layout icon
+	icon := layoutIcon(name)
+	x1, y1 := iconSize-iconMargin, iconSize-iconMargin
 
 	// Draw hint rectangle
 	if common.HasUnseenInfos() {
@@ -94,6 +63,25 @@ func UpdateIcon(ws *desktop.Workspace) {
 	systray.SetIcon(data.Bytes())
 }
 
+func UpdateTooltip(ws *desktop.Workspace) {
+	location := store.Location{Desktop: store.Workplace.CurrentDesktop, Screen: store.Workplace.CurrentScreen}
+	if ws == nil || ws.Location != location || len(common.Config.TilingIcon) == 0 {
+		return
+	}
+
+	// Obtain tooltip text
+	tooltip := fmt.Sprintf("%s - tiling manager", common.Build.Name)
+	if paused := ws.PausedFor(); paused > 0 {
+		tooltip = fmt.Sprintf("%s - tiling paused (%s)", common.Build.Name, paused.Round(time.Second))
+	}
+	if ws.Locked {
+		tooltip = fmt.Sprintf("%s (layout locked)", tooltip)
+	}
+
+	// Update systray tooltip
+	systray.SetTooltip(tooltip)
+}
+
 func HintIcon(active bool) []byte {
 	if !active {
 		return EmptyIcon()
@@ -128,6 +116,48 @@ func EmptyIcon() []byte {
 	return data.Bytes()
 }
 
+func layoutIcon(name string) *image.RGBA {
+
+	// Initialize image
+	col := image.Uniform{rgba("icon_foreground")}
+	icon := image.NewRGBA(image.Rect(0, 0, iconSize, iconSize))
+
+	// Draw background rectangle
+	x0, y0, x1, y1 := iconMargin, iconMargin, iconSize-iconMargin, iconSize-iconMargin
+	draw.Draw(icon, icon.Bounds(), &image.Uniform{rgba("icon_background")}, image.Point{}, draw.Src)
+
+	// Draw layout rectangles
+	switch name {
+	case "vertical-left":
+		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
+	case "vertical-right":
+		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y1), &col, image.Point{}, draw.Src)
+	case "horizontal-top":
+		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
+	case "horizontal-bottom":
+		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
+	case "maximized":
+		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/5-layoutMargin/2), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/5+layoutMargin/2, x1, y1), &col, image.Point{}, draw.Src)
+	case "fullscreen":
+		draw.Draw(icon, image.Rect(x0, y0, x1, y1), &col, image.Point{}, draw.Src)
+	case "disabled":
+		draw.Draw(icon, image.Rect(x0, y0, x0+2*layoutMargin, y1-2*layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0, y0, x1-2*layoutMargin, y0+2*layoutMargin), &col, image.Point{}, draw.Src)
+		draw.Draw(icon, image.Rect(x0+2*layoutMargin+20, y0+2*layoutMargin+20, x1, y1), &col, image.Point{}, draw.Src)
+	}
+
+	return icon
+}
+
 func rgba(name string) color.RGBA {
 	r, g, b, a := bgra(name).RGBA()
 
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"image/draw"
+
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/motif"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	indicatorSize int = 24 // Size of indicator window
+)
+
+var (
+	indicators      map[uint]*xwindow.Window = make(map[uint]*xwindow.Window) // Indicator windows per screen
+	indicatorStates map[uint]string          = make(map[uint]string)          // Painted indicator states per screen
+)
+
+func UpdateIndicators(tr *desktop.Tracker) {
+	corner := common.Config.TilingIndicator
+
+	// Remove indicators if disabled on config reload
+	if len(corner) == 0 {
+		for screen, win := range indicators {
+			win.Destroy()
+			delete(indicators, screen)
+			delete(indicatorStates, screen)
+		}
+		return
+	}
+
+	// Update indicator of each screen
+	for screen := uint(0); screen < store.Workplace.ScreenCount; screen++ {
+		location := store.Location{Desktop: store.Workplace.CurrentDesktop, Screen: screen}
+		if ws, ok := tr.Workspaces[location]; ok {
+			showIndicator(ws, corner)
+		}
+	}
+
+	// Remove indicators of vanished screens
+	for screen, win := range indicators {
+		if screen >= store.Workplace.ScreenCount {
+			win.Destroy()
+			delete(indicators, screen)
+			delete(indicatorStates, screen)
+		}
+	}
+}
+
+func showIndicator(ws *desktop.Workspace, corner string) {
+
+	// Obtain layout name
+	name := ws.ActiveLayout().GetName()
+	if ws.TilingDisabled() || ws.PausedFor() > 0 {
+		name = "disabled"
+	}
+
+	// Calculate scaled indicator size
+	size := int(math.Round(float64(indicatorSize) * store.ScreenScale(ws.Location.Screen)))
+
+	// Calculate indicator position
+	dim := store.DesktopGeometry(ws.Location.Screen)
+	x, y := dim.X+rectMargin, dim.Y+rectMargin
+	switch corner {
+	case "top_right":
+		x = dim.X + dim.Width - size - rectMargin
+	case "bottom_left":
+		y = dim.Y + dim.Height - size - rectMargin
+	case "bottom_right":
+		x, y = dim.X+dim.Width-size-rectMargin, dim.Y+dim.Height-size-rectMargin
+	}
+
+	// Skip repaint of unchanged indicator
+	bg := bgra("gui_background")
+	state := fmt.Sprint(name, x, y, size, bg)
+	if indicatorStates[ws.Location.Screen] == state {
+		return
+	}
+
+	// Draw layout icon on opaque canvas
+	cv := xgraphics.New(store.X, image.Rect(0, 0, size, size))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+	draw.Draw(cv, cv.Bounds(), xgraphics.Scale(layoutIcon(name), size, size), image.Point{}, draw.Over)
+
+	// Create or move the indicator window
+	win, ok := indicators[ws.Location.Screen]
+	if !ok {
+		win = createIndicator(x, y, size)
+		if win == nil {
+			cv.Destroy()
+			return
+		}
+		indicators[ws.Location.Screen] = win
+	} else {
+		win.MoveResize(x, y, size, size)
+	}
+
+	// Paint the image
+	cv.XSurfaceSet(win.Id)
+	cv.XDraw()
+	cv.XPaint(win.Id)
+	cv.Destroy()
+
+	indicatorStates[ws.Location.Screen] = state
+}
+
+func createIndicator(x int, y int, size int) *xwindow.Window {
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Indicator generation failed: ", err)
+		return nil
+	}
+
+	// Create the indicator window
+	win.Create(store.X.RootWin(), x, y, size, size, 0)
+
+	// Set class and name
+	icccm.WmClassSet(win.X, win.Id, &icccm.WmClass{
+		Instance: common.Build.Name,
+		Class:    common.Build.Name,
+	})
+	icccm.WmNameSet(win.X, win.Id, common.Build.Name)
+
+	// Set type and states for always on top behavior
+	ewmh.WmWindowTypeSet(win.X, win.Id, []string{
+		"_NET_WM_WINDOW_TYPE_DOCK",
+	})
+	ewmh.WmStateSet(win.X, win.Id, []string{
+		"_NET_WM_STATE_SKIP_TASKBAR",
+		"_NET_WM_STATE_SKIP_PAGER",
+		"_NET_WM_STATE_STICKY",
+		"_NET_WM_STATE_ABOVE",
+	})
+	ewmh.WmDesktopSet(win.X, win.Id, 0xFFFFFFFF)
+
+	// Set hints for position, focus and decorations
+	icccm.WmNormalHintsSet(win.X, win.Id, &icccm.NormalHints{
+		Flags: icccm.SizeHintPPosition,
+		X:     x,
+		Y:     y,
+	})
+	icccm.WmHintsSet(win.X, win.Id, &icccm.Hints{
+		Flags: icccm.HintInput,
+		Input: 0,
+	})
+	motif.WmHintsSet(win.X, win.Id, &motif.Hints{
+		Flags:      motif.HintFunctions | motif.HintDecorations,
+		Function:   motif.FunctionNone,
+		Decoration: motif.DecorationNone,
+	})
+
+	// Map the window
+	win.Map()
+
+	return win
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"image"
 	"math"
 	"time"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/BurntSushi/freetype-go/freetype/truetype"
 
+	"github.com/jezek/xgb/xproto"
+
 	"github.com/jezek/xgbutil/ewmh"
 	"github.com/jezek/xgbutil/icccm"
 	"github.com/jezek/xgbutil/motif"
@@ -49,28 +52,157 @@ func ShowLayout(ws *desktop.Workspace) {
 		if ws.TilingDisabled() {
 			name = "disabled"
 		}
+		if ws.Locked {
+			name += " (locked)"
+		}
 
 		// Calculate scaled desktop dimensions
 		dim := dimensions(ws)
 		_, _, w, h := scale(dim.X, dim.Y, dim.Width, dim.Height)
 
+		// Calculate scaled font size
+		size := int(math.Round(float64(fontSize) * store.ScreenScale(ws.Location.Screen)))
+
 		// Create an empty canvas image
 		bg := bgra("gui_background")
-		cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectMargin, h+fontSize+2*fontMargin+2*rectMargin))
+		cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectMargin, h+size+2*fontMargin+2*rectMargin))
 		cv.For(func(x int, y int) xgraphics.BGRA { return bg })
 
 		// Draw client rectangles
-		drawClients(cv, ws, name)
+		drawClients(cv, ws, name, nil)
 
 		// Draw layout name
-		drawText(cv, name, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, fontSize)
+		drawText(cv, name, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, size)
 
 		// Show the canvas graphics
 		showGraphics(cv, ws, time.Duration(common.Config.TilingGui))
 	})
 }
 
-func drawClients(cv *xgraphics.Image, ws *desktop.Workspace, layout string) {
+func ShowProportion(ws *desktop.Workspace, proportion float64) {
+	if ws == nil || common.Config.TilingGui <= 0 {
+		return
+	}
+
+	// Obtain proportion text
+	txt := fmt.Sprintf("%d%%", int(math.Round(proportion*100)))
+
+	// Calculate scaled font size
+	size := int(math.Round(float64(fontSize) * store.ScreenScale(ws.Location.Screen)))
+
+	// Create an empty canvas image
+	bg := bgra("gui_background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, 4*size+2*rectMargin, size+2*fontMargin+2*rectMargin))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw proportion text
+	drawText(cv, txt, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, size)
+
+	// Show the canvas graphics
+	showGraphics(cv, ws, time.Duration(common.Config.TilingGui))
+}
+
+func ShowDrop(ws *desktop.Workspace, target *store.Client, side string) {
+	if ws == nil || target == nil || common.Config.TilingGui <= 0 {
+		return
+	}
+
+	// Show drop target until timeout
+	showTarget(ws, target, side, time.Duration(common.Config.TilingGui))
+}
+
+func ShowTarget(ws *desktop.Workspace, target *store.Client) {
+	if ws == nil || target == nil || common.Config.TilingGui <= 0 {
+		return
+	}
+
+	// Show swap target until selection ends
+	showTarget(ws, target, "swap", 0)
+}
+
+func ShowSelection(tr *desktop.Tracker, ws *desktop.Workspace) {
+	if ws == nil || common.Config.TilingGui <= 0 {
+		return
+	}
+
+	// Calculate scaled desktop dimensions
+	dim := dimensions(ws)
+	_, _, w, h := scale(dim.X, dim.Y, dim.Width, dim.Height)
+
+	// Calculate scaled font size
+	size := int(math.Round(float64(fontSize) * store.ScreenScale(ws.Location.Screen)))
+
+	// Create an empty canvas image
+	bg := bgra("gui_background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectMargin, h+size+2*fontMargin+2*rectMargin))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw client rectangles with selected clients
+	drawClients(cv, ws, ws.ActiveLayout().GetName(), tr.Selected)
+
+	// Draw selection count
+	txt := fmt.Sprintf("%d selected", len(tr.Selected))
+	drawText(cv, txt, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, size)
+
+	// Show the canvas graphics
+	showGraphics(cv, ws, time.Duration(common.Config.TilingGui))
+}
+
+func HideOverlay(ws *desktop.Workspace) {
+	if ws == nil {
+		return
+	}
+
+	// Close opened window
+	if v, ok := gui[ws.Location.Screen]; ok {
+		v.Destroy()
+		delete(gui, ws.Location.Screen)
+	}
+}
+
+func showTarget(ws *desktop.Workspace, target *store.Client, side string, duration time.Duration) {
+
+	// Calculate scaled desktop dimensions
+	dim := dimensions(ws)
+	_, _, w, h := scale(dim.X, dim.Y, dim.Width, dim.Height)
+
+	// Calculate scaled font size
+	size := int(math.Round(float64(fontSize) * store.ScreenScale(ws.Location.Screen)))
+
+	// Create an empty canvas image
+	bg := bgra("gui_background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectMargin, h+size+2*fontMargin+2*rectMargin))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw client rectangles
+	drawClients(cv, ws, ws.ActiveLayout().GetName(), nil)
+
+	// Calculate scaled drop dimensions
+	tx, ty, tw, th := target.OuterGeometry()
+	x, y, w, h := scale(tx-dim.X, ty-dim.Y, tw, th)
+	switch {
+	case side == "before" && w > h:
+		w /= 2
+	case side == "before":
+		h /= 2
+	case side == "after" && w > h:
+		x, w = x+w/2, w/2
+	case side == "after":
+		y, h = y+h/2, h/2
+	}
+
+	// Draw drop rectangle onto canvas
+	color := bgra("gui_client_drop")
+	drawImage(cv, &image.Uniform{color}, color, x+rectMargin, y+rectMargin, x+w, y+h)
+
+	// Draw drop behavior
+	drawText(cv, side, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, size)
+
+	// Show the canvas graphics
+	showGraphics(cv, ws, duration)
+}
+
+func drawClients(cv *xgraphics.Image, ws *desktop.Workspace, layout string, selected map[xproto.Window]bool) {
 	al := ws.ActiveLayout()
 	mg := al.GetManager()
 	clients := ws.VisibleClients()
@@ -114,6 +246,9 @@ func drawClients(cv *xgraphics.Image, ws *desktop.Workspace, layout string) {
 		if mg.IsMaster(c) || common.IsInList(layout, []string{"maximized", "fullscreen"}) {
 			color = bgra("gui_client_master")
 		}
+		if selected[c.Window.Id] {
+			color = bgra("gui_client_select")
+		}
 
 		// Draw client rectangle onto canvas
 		drawImage(cv, &image.Uniform{color}, color, x+rectMargin, y+rectMargin, x+w, y+h)
@@ -163,7 +298,7 @@ func showGraphics(img *xgraphics.Image, ws *desktop.Workspace, duration time.Dur
 	// Calculate window dimensions
 	dim := dimensions(ws)
 	w, h := img.Rect.Dx(), img.Rect.Dy()
-	x, y := dim.X+dim.Width/2-w/2, dim.Y+dim.Height/2-h/2
+	x, y := position(dim, w, h)
 
 	// Create the graphics window
 	win.Create(img.X.RootWin(), x, y, w, h, 0)
@@ -232,6 +367,37 @@ func showGraphics(img *xgraphics.Image, ws *desktop.Workspace, duration time.Dur
 	return win
 }
 
+func position(dim *common.Geometry, w int, h int) (int, int) {
+	popups := store.PopupsGet(store.X)
+
+	// Candidate positions (center, top, bottom, left, right)
+	candidates := []common.Point{
+		{X: dim.X + dim.Width/2 - w/2, Y: dim.Y + dim.Height/2 - h/2},
+		{X: dim.X + dim.Width/2 - w/2, Y: dim.Y + rectMargin},
+		{X: dim.X + dim.Width/2 - w/2, Y: dim.Y + dim.Height - h - rectMargin},
+		{X: dim.X + rectMargin, Y: dim.Y + dim.Height/2 - h/2},
+		{X: dim.X + dim.Width - w - rectMargin, Y: dim.Y + dim.Height/2 - h/2},
+	}
+
+	// Avoid covering open menus and tooltips
+	for _, p := range candidates {
+		overlay := common.Geometry{X: p.X, Y: p.Y, Width: w, Height: h}
+		covered := false
+		for _, popup := range popups {
+			covered = covered || intersects(overlay, popup)
+		}
+		if !covered {
+			return p.X, p.Y
+		}
+	}
+
+	return candidates[0].X, candidates[0].Y
+}
+
+func intersects(a common.Geometry, b common.Geometry) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
+
 func dimensions(ws *desktop.Workspace) *common.Geometry {
 	dim := store.DesktopGeometry(ws.Location.Screen)
 